@@ -0,0 +1,53 @@
+package dataloader
+
+import "sync/atomic"
+
+// AutoPrime configures the priming of the keys returned by the batchFn that
+// were not requested, so that loading them later is a cache hit.
+type AutoPrime[K comparable, T any] struct {
+	// Reports whether the unrequested key is primed. Nil primes all the keys.
+	Filter func(key K, val T) bool
+
+	// Stops priming once the cache holds this many keys. Zero means no
+	// limit.
+	MaxKeys int
+}
+
+// autoPrime primes an unrequested key, unless it is already cached or
+// pending.
+func (l *Dataloader[K, T]) autoPrime(key K, val T) {
+	cfg := l.autoPrimeCfg
+	if cfg == nil {
+		return
+	}
+
+	if cfg.Filter != nil && !cfg.Filter(key, val) {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if cfg.MaxKeys > 0 && len(l.data) >= cfg.MaxKeys {
+		return
+	}
+
+	if r, ok := l.data[key]; ok && l.freshness(key, r) != expired {
+		return
+	}
+
+	l.prime(key, val, nil)
+	atomic.AddInt64(&l.stats.autoPrimed, 1)
+}
+
+// PrimeInto primes dst with the values loaded by src, keyed by keyFn. For
+// example, users loaded by email can prime the loader of users by ID. Only
+// values returned by the batchFn of src are primed into dst, so loaders can
+// prime each other without looping.
+func PrimeInto[K1, K2 comparable, T any](src *Dataloader[K1, T], dst *Dataloader[K2, T], keyFn func(T) K2) {
+	src.mu.Lock()
+	src.listeners = append(src.listeners, func(val T) {
+		dst.PrimeIfAbsent(keyFn(val), val)
+	})
+	src.mu.Unlock()
+}
@@ -0,0 +1,112 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func TestBisect(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("poison key")
+
+	var calls int
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		calls++
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			if key == 3 {
+				return nil, wantErr
+			}
+
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	var events []dataloader.Event[int]
+	dl := dataloader.NewManual(context.Background(), fetchNumber,
+		dataloader.WithBisect[int, string](3),
+		dataloader.WithObserver[int, string](func(e dataloader.Event[int]) {
+			events = append(events, e)
+		}),
+	)
+
+	results := dl.LoadMany([]int{1, 2, 3, 4, 5, 6, 7, 8})
+	dl.Dispatch()
+
+	for key, res := range results {
+		val, err := res.Unwrap()
+		if key == 3 {
+			if !errors.Is(err, wantErr) {
+				t.Fatalf("expected %v, got %v", wantErr, err)
+			}
+
+			continue
+		}
+
+		if err != nil || val != fmt.Sprint(key) {
+			t.Fatalf("expected %v, got %v, %v", key, val, err)
+		}
+	}
+
+	// 1 full batch, 2 halves, 2 quarters and 2 eighths.
+	if exp, got := 7, calls; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if exp, got := 1, len(events); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if exp, got := "bisect [3]", fmt.Sprint(events[0].Type, " ", events[0].Keys); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestBisectTimeout(t *testing.T) {
+	t.Parallel()
+
+	hung := make(chan struct{})
+	t.Cleanup(func() {
+		close(hung)
+	})
+
+	var calls int64
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		atomic.AddInt64(&calls, 1)
+
+		// Ignores the context.
+		<-hung
+
+		return nil, nil
+	}
+
+	dl := dataloader.NewManual(context.Background(), fetchNumber,
+		dataloader.WithBatchTimeout[int, string](10*time.Millisecond),
+		dataloader.WithBisect[int, string](3),
+	)
+
+	results := dl.LoadMany([]int{1, 2, 3, 4})
+	dl.Dispatch()
+
+	for _, res := range results {
+		if _, err := res.Wait(); !errors.Is(err, dataloader.ErrBatchTimeout) {
+			t.Fatalf("expected %v, got %v", dataloader.ErrBatchTimeout, err)
+		}
+	}
+
+	// The timed out batch is not bisected, which would give each half a
+	// new timeout.
+	if exp, got := int64(1), atomic.LoadInt64(&calls); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
@@ -0,0 +1,253 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker configures the circuit breaker around the batchFn. The
+// circuit opens when either threshold is reached, and a single probe batch is
+// allowed through after the open timeout.
+type CircuitBreaker struct {
+	// Opens the circuit after this many consecutive failed batches.
+	ConsecutiveFailures int
+
+	// Opens the circuit when the ratio of failed batches in the window reaches
+	// this value.
+	ErrorRate float64
+
+	// How many of the most recent batches the error rate is computed over.
+	// The error rate applies only once the window is full.
+	Window int
+
+	// How long the circuit stays open before a probe batch is allowed.
+	OpenTimeout time.Duration
+}
+
+const (
+	defaultConsecutiveFailures = 5
+	defaultBreakerWindow       = 20
+	defaultOpenTimeout         = 5 * time.Second
+)
+
+type breaker struct {
+	mu       sync.Mutex
+	cfg      CircuitBreaker
+	state    CircuitState
+	failures int
+	window   []bool
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(cfg CircuitBreaker) *breaker {
+	if cfg.ConsecutiveFailures <= 0 && cfg.ErrorRate <= 0 {
+		cfg.ConsecutiveFailures = defaultConsecutiveFailures
+	}
+
+	if cfg.ErrorRate > 0 && cfg.Window <= 0 {
+		cfg.Window = defaultBreakerWindow
+	}
+
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = defaultOpenTimeout
+	}
+
+	return &breaker{cfg: cfg}
+}
+
+// open reports whether new keys should fail fast.
+func (b *breaker) open(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		return now.Sub(b.openedAt) < b.cfg.OpenTimeout
+	case CircuitHalfOpen:
+		return b.probing
+	default:
+		return false
+	}
+}
+
+// allow reports whether a batch may call the batchFn, and the new state if it
+// changed.
+func (b *breaker) allow(now time.Time) (bool, CircuitState, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) < b.cfg.OpenTimeout {
+			return false, b.state, false
+		}
+
+		b.state = CircuitHalfOpen
+		b.probing = true
+
+		return true, b.state, true
+	case CircuitHalfOpen:
+		if b.probing {
+			return false, b.state, false
+		}
+
+		b.probing = true
+
+		return true, b.state, false
+	default:
+		return true, b.state, false
+	}
+}
+
+// record records the outcome of a batch, and returns the new state if it
+// changed.
+func (b *breaker) record(now time.Time, failed bool) (CircuitState, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prev := b.state
+
+	switch b.state {
+	case CircuitHalfOpen:
+		b.probing = false
+
+		if failed {
+			b.trip(now)
+		} else {
+			b.reset()
+		}
+	case CircuitClosed:
+		if failed {
+			b.failures++
+		} else {
+			b.failures = 0
+		}
+
+		if b.cfg.Window > 0 {
+			b.window = append(b.window, failed)
+			if len(b.window) > b.cfg.Window {
+				b.window = b.window[1:]
+			}
+		}
+
+		if b.cfg.ConsecutiveFailures > 0 && b.failures >= b.cfg.ConsecutiveFailures ||
+			b.cfg.ErrorRate > 0 && len(b.window) == b.cfg.Window && b.errorRate() >= b.cfg.ErrorRate {
+			b.trip(now)
+		}
+	}
+
+	return b.state, b.state != prev
+}
+
+// abort ends the probe of a batch whose outcome is not recorded, so that the
+// next batch probes again.
+func (b *breaker) abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitHalfOpen {
+		b.probing = false
+	}
+}
+
+// restart closes the circuit, and reports whether the state changed.
+func (b *breaker) restart() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	changed := b.state != CircuitClosed
+	b.probing = false
+	b.reset()
+
+	return changed
+}
+
+func (b *breaker) errorRate() float64 {
+	var failed int
+	for _, f := range b.window {
+		if f {
+			failed++
+		}
+	}
+
+	return float64(failed) / float64(len(b.window))
+}
+
+func (b *breaker) trip(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+}
+
+func (b *breaker) reset() {
+	b.state = CircuitClosed
+	b.failures = 0
+	b.window = nil
+}
+
+// guard runs the batch when the circuit breaker allows it, and records a
+// single outcome for the batch, however many times it is bisected.
+func (l *Dataloader[K, T]) guard(ctx context.Context, keys []K, results map[K]*Result[T], emit func(K, T, error)) {
+	if l.breaker == nil {
+		l.call(ctx, keys, results, emit, 0)
+
+		return
+	}
+
+	ok, state, changed := l.breaker.allow(l.clock.Now())
+	if changed {
+		l.notifyCircuit(state)
+	}
+
+	if !ok {
+		// Like keys loaded while the circuit is open, the keys are not
+		// cached, so that they are loaded again once it closes.
+		l.mu.Lock()
+		for key, r := range results {
+			if l.data[key] == r {
+				delete(l.data, key)
+				delete(l.loadedAt, key)
+			}
+		}
+		l.mu.Unlock()
+
+		for _, r := range results {
+			r.reject(ErrCircuitOpen)
+		}
+
+		return
+	}
+
+	err := l.call(ctx, keys, results, emit, 0)
+
+	// Batches cancelled by flush do not count.
+	if ctx.Err() == nil || err == nil {
+		if state, changed := l.breaker.record(l.clock.Now(), err != nil); changed {
+			l.notifyCircuit(state)
+		}
+	} else {
+		l.breaker.abort()
+	}
+}
@@ -0,0 +1,288 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("service unavailable")
+
+	var calls int
+	down := true
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		calls++
+		if down {
+			return nil, wantErr
+		}
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	clock := dataloader.NewFakeClock(time.Unix(0, 0))
+
+	var states []dataloader.CircuitState
+	dl := dataloader.NewManual(context.Background(), fetchNumber,
+		dataloader.WithClock[int, string](clock),
+		dataloader.WithCircuitBreaker[int, string](dataloader.CircuitBreaker{
+			ConsecutiveFailures: 2,
+			OpenTimeout:         time.Minute,
+		}),
+		dataloader.WithObserver[int, string](func(e dataloader.Event[int]) {
+			if e.Type == dataloader.EventCircuit {
+				states = append(states, e.State)
+			}
+		}),
+	)
+
+	for _, key := range []int{1, 2} {
+		res := dl.Load(key)
+		dl.Dispatch()
+
+		if _, err := res.Unwrap(); !errors.Is(err, wantErr) {
+			t.Fatalf("expected %v, got %v", wantErr, err)
+		}
+	}
+
+	// Fails fast without calling the batchFn.
+	if _, err := dl.Load(3).Unwrap(); !errors.Is(err, dataloader.ErrCircuitOpen) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrCircuitOpen, err)
+	}
+	dl.Dispatch()
+
+	if exp, got := 2, calls; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	down = false
+	clock.Advance(time.Minute)
+
+	res := dl.Load(3)
+	dl.Dispatch()
+
+	if val, err := res.Unwrap(); err != nil || val != "3" {
+		t.Fatalf("expected 3, got %v, %v", val, err)
+	}
+
+	if exp, got := "[open half-open closed]", fmt.Sprint(states); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestCircuitBreakerErrorRate(t *testing.T) {
+	t.Parallel()
+
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		if keys[0]%2 == 0 {
+			return nil, errors.New("even")
+		}
+
+		return map[int]string{keys[0]: fmt.Sprint(keys[0])}, nil
+	}
+
+	dl := dataloader.NewManual(context.Background(), fetchNumber,
+		dataloader.WithCircuitBreaker[int, string](dataloader.CircuitBreaker{
+			ErrorRate: 0.5,
+			Window:    4,
+		}),
+	)
+
+	for i := 1; i <= 4; i++ {
+		dl.Load(i)
+		dl.Dispatch()
+	}
+
+	if _, err := dl.Load(5).Unwrap(); !errors.Is(err, dataloader.ErrCircuitOpen) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrCircuitOpen, err)
+	}
+}
+
+func TestCircuitBreakerCancelledProbe(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	wantErr := errors.New("service unavailable")
+	down := true
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		if down {
+			return nil, wantErr
+		}
+
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	}
+
+	clock := dataloader.NewFakeClock(time.Unix(0, 0))
+
+	dl := dataloader.NewManual(ctx, fetchNumber,
+		dataloader.WithClock[int, string](clock),
+		dataloader.WithCircuitBreaker[int, string](dataloader.CircuitBreaker{
+			ConsecutiveFailures: 1,
+			OpenTimeout:         time.Minute,
+		}),
+	)
+
+	dl.Load(1)
+	dl.Dispatch()
+
+	// The probe is cancelled, and its outcome is not recorded.
+	down = false
+	clock.Advance(time.Minute)
+	cancel()
+
+	res := dl.Load(2)
+	dl.Dispatch()
+
+	if _, err := res.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+
+	// The next batch probes again.
+	if _, err := dl.Load(3).Peek(); !errors.Is(err, dataloader.ErrNoResult) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrNoResult, err)
+	}
+}
+
+func TestCircuitBreakerReset(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("service unavailable")
+	down := true
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		if down {
+			return nil, wantErr
+		}
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	dl, flush := dataloader.New(context.Background(), fetchNumber,
+		dataloader.WithCircuitBreaker[int, string](dataloader.CircuitBreaker{
+			ConsecutiveFailures: 1,
+			OpenTimeout:         time.Hour,
+		}),
+	)
+	t.Cleanup(flush)
+
+	if _, err := dl.Load(1); !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+
+	if _, err := dl.Load(2); !errors.Is(err, dataloader.ErrCircuitOpen) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrCircuitOpen, err)
+	}
+
+	// Reset closes the circuit.
+	down = false
+	dl.Reset()
+
+	if val, err := dl.Load(2); err != nil || val != "2" {
+		t.Fatalf("expected 2, got %v, %v", val, err)
+	}
+}
+
+func TestCircuitBreakerBisect(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("poison key")
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		res := make(map[int]string)
+		for _, key := range keys {
+			if key == 0 {
+				return nil, wantErr
+			}
+
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	dl := dataloader.NewManual(context.Background(), fetchNumber,
+		dataloader.WithBisect[int, string](4),
+		dataloader.WithCircuitBreaker[int, string](dataloader.CircuitBreaker{
+			ConsecutiveFailures: 5,
+		}),
+	)
+
+	keys := make([]int, 16)
+	for i := range keys {
+		keys[i] = i
+	}
+	dl.LoadMany(keys)
+	dl.Dispatch()
+
+	// The bisected batch counts as a single failure.
+	if _, err := dl.Load(100).Peek(); !errors.Is(err, dataloader.ErrNoResult) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrNoResult, err)
+	}
+}
+
+func TestCircuitBreakerQueued(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("service unavailable")
+	down := true
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		if down {
+			return nil, wantErr
+		}
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	clock := dataloader.NewFakeClock(time.Unix(0, 0))
+
+	dl := dataloader.NewManual(context.Background(), fetchNumber,
+		dataloader.WithClock[int, string](clock),
+		dataloader.WithBatchMaxKeys[int, string](1),
+		dataloader.WithCircuitBreaker[int, string](dataloader.CircuitBreaker{
+			ConsecutiveFailures: 1,
+			OpenTimeout:         time.Minute,
+		}),
+	)
+
+	// The second batch was queued before the first one opened the circuit.
+	results := dl.LoadMany([]int{1, 2})
+	dl.Dispatch()
+
+	if _, err := results[2].Wait(); !errors.Is(err, dataloader.ErrCircuitOpen) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrCircuitOpen, err)
+	}
+
+	// The rejection is not cached.
+	down = false
+	clock.Advance(time.Minute)
+
+	res := dl.Load(2)
+	dl.Dispatch()
+
+	if val, err := res.Wait(); err != nil || val != "2" {
+		t.Fatalf("expected 2, got %v, %v", val, err)
+	}
+}
@@ -0,0 +1,228 @@
+package dataloader
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// RefreshAhead configures the refresh of hot keys shortly before they expire,
+// so that loading them never waits for a batch. It requires a TTL.
+type RefreshAhead struct {
+	// How long before the expiry the keys are refreshed.
+	Window time.Duration
+
+	// How many times a key must be loaded since it was last loaded from the
+	// batchFn to be refreshed. Defaults to 1.
+	MinHits int
+
+	// The maximum number of keys refreshed per interval. Zero means no limit.
+	Limit int
+
+	// How often the cache is scanned for keys to refresh. Defaults to a
+	// quarter of the window.
+	Interval time.Duration
+}
+
+type refresh[T any] struct {
+	stale *Result[T]
+	next  *Result[T]
+}
+
+// lookup returns the result of the key, and the result to settle in the next
+// batch when the key has to be loaded. For a stale key, the two differ. It
+// must be called with the lock held.
+func (l *Dataloader[K, T]) lookup(key K) (res, next *Result[T]) {
+	res, found := l.data[key]
+	if found {
+		if l.refreshAheadCfg != nil {
+			l.hits[key]++
+		}
+
+		rf, refreshing := l.refreshing[key]
+
+		switch l.freshness(key, res) {
+		case fresh:
+			return res, nil
+		case stale:
+			if refreshing {
+				return res, nil
+			}
+
+			next = l.newResult()
+			l.refreshing[key] = refresh[T]{
+				stale: res,
+				next:  next,
+			}
+
+			return res, next
+		case expired:
+			// Wait for the refresh that is already in flight.
+			if refreshing {
+				delete(l.refreshing, key)
+				l.data[key] = rf.next
+
+				return rf.next, nil
+			}
+		}
+	}
+
+	// Fail fast without caching the key, so that it is loaded again once the
+	// circuit closes.
+	if l.breaker != nil && l.breaker.open(l.clock.Now()) {
+		return Reject[T](ErrCircuitOpen), nil
+	}
+
+	res = l.newResult()
+	l.data[key] = res
+
+	return res, res
+}
+
+const (
+	fresh = iota
+	stale
+	expired
+)
+
+func (l *Dataloader[K, T]) freshness(key K, res *Result[T]) int {
+	if l.ttl <= 0 && l.staleAfter <= 0 || !res.Ready() {
+		return fresh
+	}
+
+	at, ok := l.loadedAt[key]
+	if !ok {
+		return fresh
+	}
+
+	age := l.clock.Now().Sub(at)
+	if l.ttl > 0 && age >= l.ttl {
+		return expired
+	}
+
+	if l.staleAfter > 0 && age >= l.staleAfter {
+		// Errors are not served stale.
+		if res.Error() != nil {
+			return expired
+		}
+
+		return stale
+	}
+
+	return fresh
+}
+
+// touch records when the cached result of the key was settled. It must be
+// called with the lock held.
+func (l *Dataloader[K, T]) touch(key K) {
+	if l.ttl > 0 || l.staleAfter > 0 {
+		l.loadedAt[key] = l.clock.Now()
+	}
+}
+
+// settled updates the cache once the results of a batch are settled. A
+// refreshed result replaces the stale one, unless the refresh failed.
+func (l *Dataloader[K, T]) settled(results map[K]*Result[T]) {
+	if l.ttl <= 0 && l.staleAfter <= 0 {
+		return
+	}
+
+	var failed []K
+	var err error
+
+	l.mu.Lock()
+	for key, r := range results {
+		if rf, ok := l.refreshing[key]; ok && rf.next == r {
+			delete(l.refreshing, key)
+
+			// Replaced in the meantime.
+			if l.data[key] != rf.stale {
+				continue
+			}
+
+			if r.Error() != nil {
+				failed = append(failed, key)
+				err = r.Error()
+
+				continue
+			}
+
+			l.data[key] = r
+			delete(l.hits, key)
+		}
+
+		if l.data[key] == r {
+			l.touch(key)
+		}
+	}
+	l.mu.Unlock()
+
+	if len(failed) > 0 {
+		l.notify(Event[K]{
+			Type: EventRefreshError,
+			Keys: failed,
+			Err:  err,
+		})
+	}
+}
+
+// refreshAhead returns the hot keys that are about to expire, with their
+// refresh pending, so that they are batched with the other keys.
+func (l *Dataloader[K, T]) refreshAhead() []K {
+	cfg := l.refreshAheadCfg
+	if cfg == nil || l.ttl <= 0 {
+		return nil
+	}
+
+	now := l.clock.Now()
+
+	l.mu.Lock()
+	if now.Sub(l.lastScan) < cfg.Interval {
+		l.mu.Unlock()
+
+		return nil
+	}
+	l.lastScan = now
+
+	var keys []K
+	for key, at := range l.loadedAt {
+		if cfg.Limit > 0 && len(keys) >= cfg.Limit {
+			break
+		}
+
+		if l.hits[key] < cfg.MinHits || now.Sub(at) < l.ttl-cfg.Window {
+			continue
+		}
+
+		if _, ok := l.refreshing[key]; ok {
+			continue
+		}
+
+		if _, ok := l.pending[key]; ok {
+			continue
+		}
+
+		res := l.data[key]
+		if res == nil || l.freshness(key, res) == expired || res.Error() != nil {
+			continue
+		}
+
+		next := l.newResult()
+		l.refreshing[key] = refresh[T]{
+			stale: res,
+			next:  next,
+		}
+		l.pending[key] = next
+		keys = append(keys, key)
+	}
+	l.mu.Unlock()
+
+	if len(keys) > 0 {
+		atomic.AddInt64(&l.stats.refreshedAhead, int64(len(keys)))
+		l.notify(Event[K]{
+			Type: EventRefreshAhead,
+			Keys: keys,
+		})
+	}
+
+	return keys
+}
@@ -0,0 +1,224 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("want error")
+
+	var version int
+	var fail bool
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		if fail {
+			return nil, wantErr
+		}
+
+		version++
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprintf("%d-v%d", key, version)
+		}
+
+		return res, nil
+	}
+
+	clock := dataloader.NewFakeClock(time.Unix(0, 0))
+
+	var events []dataloader.Event[int]
+	dl := dataloader.NewManual(context.Background(), fetchNumber,
+		dataloader.WithClock[int, string](clock),
+		dataloader.WithStaleWhileRevalidate[int, string](time.Minute),
+		dataloader.WithTTL[int, string](time.Hour),
+		dataloader.WithObserver[int, string](func(e dataloader.Event[int]) {
+			events = append(events, e)
+		}),
+	)
+
+	load := func(ready bool, exp string) {
+		t.Helper()
+
+		res := dl.Load(1)
+		if res.Ready() != ready {
+			t.Fatalf("expected ready %v, got %v", ready, res.Ready())
+		}
+
+		dl.Dispatch()
+
+		if got, err := res.Unwrap(); err != nil || exp != got {
+			t.Fatalf("expected %v, got %v, %v", exp, got, err)
+		}
+	}
+
+	load(false, "1-v1")
+	load(true, "1-v1")
+
+	// The stale value is returned, and refreshed in the background.
+	clock.Advance(time.Minute)
+	load(true, "1-v1")
+	load(true, "1-v2")
+
+	// The stale value is kept when the refresh fails.
+	clock.Advance(time.Minute)
+	fail = true
+	load(true, "1-v2")
+	load(true, "1-v2")
+
+	// Each load of the stale key retries the refresh.
+	if exp, got := 2, len(events); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if exp, got := dataloader.EventRefreshError, events[0].Type; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if !errors.Is(events[0].Err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, events[0].Err)
+	}
+
+	// Expired values block until they are loaded again.
+	fail = false
+	clock.Advance(time.Hour)
+	load(false, "1-v3")
+}
+
+func TestStaleWhileRevalidateBusyWorker(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		if keys[0] != 1 {
+			<-release
+		}
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	clock := dataloader.NewFakeClock(time.Unix(0, 0))
+
+	dl, flush := dataloader.New(context.Background(), fetchNumber,
+		dataloader.WithClock[int, string](clock),
+		dataloader.WithBatchMaxKeys[int, string](1),
+		dataloader.WithStaleWhileRevalidate[int, string](time.Minute),
+	)
+	t.Cleanup(func() {
+		close(release)
+		flush()
+	})
+
+	if _, err := dl.Load(1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	clock.Advance(time.Minute)
+
+	// The only worker is busy, and the background goroutine waits for it.
+	dl.LoadThunk(2)
+	dl.LoadThunk(3)
+
+	loaded := make(chan string)
+	go func() {
+		val, _ := dl.Load(1)
+		loaded <- val
+	}()
+
+	select {
+	case val := <-loaded:
+		if exp, got := "1", val; exp != got {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the stale value without blocking")
+	}
+}
+
+func TestRefreshAhead(t *testing.T) {
+	t.Parallel()
+
+	var batches [][]int
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprintf("%d-v%d", key, len(batches))
+		}
+
+		return res, nil
+	}
+
+	clock := dataloader.NewFakeClock(time.Unix(0, 0))
+
+	var refreshed []int
+	dl := dataloader.NewManual(context.Background(), fetchNumber,
+		dataloader.WithClock[int, string](clock),
+		dataloader.WithTTL[int, string](time.Hour),
+		dataloader.WithRefreshAhead[int, string](dataloader.RefreshAhead{
+			Window:  10 * time.Minute,
+			MinHits: 2,
+		}),
+		dataloader.WithObserver[int, string](func(e dataloader.Event[int]) {
+			if e.Type == dataloader.EventRefreshAhead {
+				refreshed = append(refreshed, e.Keys...)
+			}
+		}),
+	)
+
+	dl.LoadMany([]int{1, 2})
+	dl.Dispatch()
+
+	// Only the first key is hot.
+	dl.Load(1)
+	dl.Load(1)
+	dl.Load(2)
+
+	// Not within the window yet.
+	clock.Advance(45 * time.Minute)
+	dl.Dispatch()
+
+	if exp, got := 1, len(batches); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	clock.Advance(5 * time.Minute)
+	dl.Dispatch()
+
+	if exp, got := "[1]", fmt.Sprint(refreshed); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	res := dl.Load(1)
+	if val, err := res.Unwrap(); err != nil || val != "1-v2" {
+		t.Fatalf("expected 1-v2, got %v, %v", val, err)
+	}
+
+	// The refreshed key does not expire with the others.
+	clock.Advance(10 * time.Minute)
+
+	if !dl.Load(1).Ready() {
+		t.Fatal("expected the refreshed key to be cached")
+	}
+
+	if dl.Load(2).Ready() {
+		t.Fatal("expected the cold key to be expired")
+	}
+
+	if exp, got := int64(1), dl.Stats().RefreshedAhead; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
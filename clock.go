@@ -0,0 +1,172 @@
+package dataloader
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock abstracts time, so that the scheduling of batches can be tested
+// deterministically.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	NewTimer(d time.Duration) Timer
+}
+
+type Ticker interface {
+	C() <-chan time.Time
+	Reset(d time.Duration)
+	Stop()
+}
+
+type Timer interface {
+	C() <-chan time.Time
+	Reset(d time.Duration) bool
+	Stop() bool
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return &realTicker{time.NewTicker(d)}
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{time.NewTimer(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t *realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t *realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// FakeClock is a Clock that only moves when it is advanced manually.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers map[*fakeTimer]bool
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:    now,
+		timers: make(map[*fakeTimer]bool),
+	}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	return &fakeTicker{c.newTimer(d, d)}
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return c.newTimer(d, 0)
+}
+
+// Advance moves the clock forward and fires the timers and tickers that are
+// due. Like time.Ticker, a ticker that misses ticks only fires once.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	for t := range c.timers {
+		if t.at.After(c.now) {
+			continue
+		}
+
+		select {
+		case t.ch <- t.at:
+		default:
+		}
+
+		if t.period <= 0 {
+			delete(c.timers, t)
+			continue
+		}
+
+		for !t.at.After(c.now) {
+			t.at = t.at.Add(t.period)
+		}
+	}
+}
+
+func (c *FakeClock) newTimer(d, period time.Duration) *fakeTimer {
+	t := &fakeTimer{
+		clock:  c,
+		ch:     make(chan time.Time, 1),
+		period: period,
+	}
+	t.Reset(d)
+
+	return t
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	ch     chan time.Time
+	at     time.Time
+	period time.Duration
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := t.clock.timers[t]
+	t.at = t.clock.now.Add(d)
+	if t.period > 0 {
+		t.period = d
+	}
+	t.clock.timers[t] = true
+
+	return active
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	active := t.clock.timers[t]
+	delete(t.clock.timers, t)
+
+	return active
+}
+
+type fakeTicker struct {
+	*fakeTimer
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	t.fakeTimer.Reset(d)
+}
+
+func (t *fakeTicker) Stop() {
+	t.fakeTimer.Stop()
+}
@@ -0,0 +1,114 @@
+package dataloader_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func TestFakeClock(t *testing.T) {
+	t.Parallel()
+
+	clock := dataloader.NewFakeClock(time.Unix(0, 0))
+	ticker := clock.NewTicker(time.Second)
+	timer := clock.NewTimer(2 * time.Second)
+
+	fired := func(c <-chan time.Time) bool {
+		select {
+		case <-c:
+			return true
+		default:
+			return false
+		}
+	}
+
+	clock.Advance(999 * time.Millisecond)
+	if fired(ticker.C()) || fired(timer.C()) {
+		t.Fatal("expected no tick before the deadline")
+	}
+
+	clock.Advance(time.Millisecond)
+	if !fired(ticker.C()) || fired(timer.C()) {
+		t.Fatal("expected only the ticker to fire")
+	}
+
+	// Missed ticks are dropped.
+	clock.Advance(5 * time.Second)
+	if !fired(ticker.C()) || fired(ticker.C()) {
+		t.Fatal("expected the ticker to fire once")
+	}
+
+	if !fired(timer.C()) {
+		t.Fatal("expected the timer to fire")
+	}
+
+	if timer.Stop() {
+		t.Fatal("expected the timer to be expired")
+	}
+
+	ticker.Stop()
+	clock.Advance(time.Hour)
+	if fired(ticker.C()) {
+		t.Fatal("expected stopped ticker not to fire")
+	}
+
+	if exp, got := time.Unix(0, 0).Add(time.Hour+6*time.Second), clock.Now(); !exp.Equal(got) {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	t.Parallel()
+
+	var batches [][]int
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	clock := dataloader.NewFakeClock(time.Unix(0, 0))
+
+	dl, flush := dataloader.New(context.Background(), fetchNumber,
+		dataloader.WithClock[int, string](clock),
+		dataloader.WithBatchDuration[int, string](time.Hour),
+	)
+	t.Cleanup(flush)
+
+	one := dl.LoadThunk(1)
+	two := dl.LoadThunk(2)
+
+	// The batch only runs when the clock moves.
+	if one.Ready() || two.Ready() {
+		t.Fatal("expected results to be pending")
+	}
+
+	clock.Advance(time.Hour)
+
+	for i, res := range []*dataloader.Result[string]{one, two} {
+		val, err := res.Wait()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if exp, got := fmt.Sprint(i+1), val; exp != got {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	}
+
+	if exp, got := 1, len(batches); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if exp, got := 2, len(batches[0]); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
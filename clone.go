@@ -0,0 +1,94 @@
+package dataloader
+
+import "reflect"
+
+// DeepCopy returns a deep copy of the value using reflection. Pointers, maps,
+// slices, arrays, interfaces and the exported fields of structs are copied.
+// Unexported fields, channels and functions are copied shallowly.
+func DeepCopy[T any](t T) T {
+	src := reflect.ValueOf(&t).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	deepCopy(dst, src, make(map[visit]reflect.Value))
+
+	// A nil interface boxes to nil, which is the zero T.
+	v, _ := dst.Interface().(T)
+
+	return v
+}
+
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+func deepCopy(dst, src reflect.Value, seen map[visit]reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+
+		// Preserve shared and cyclic pointers.
+		v := visit{src.Pointer(), src.Type()}
+		if p, ok := seen[v]; ok {
+			dst.Set(p)
+
+			return
+		}
+
+		p := reflect.New(src.Elem().Type())
+		seen[v] = p
+		deepCopy(p.Elem(), src.Elem(), seen)
+		dst.Set(p)
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			val := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(val, iter.Value(), seen)
+			m.SetMapIndex(iter.Key(), val)
+		}
+		dst.Set(m)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(s.Index(i), src.Index(i), seen)
+		}
+		dst.Set(s)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i), seen)
+		}
+	case reflect.Struct:
+		dst.Set(src)
+
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				deepCopy(dst.Field(i), src.Field(i), seen)
+			}
+		}
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+
+		val := reflect.New(src.Elem().Type()).Elem()
+		deepCopy(val, src.Elem(), seen)
+		dst.Set(val)
+	default:
+		dst.Set(src)
+	}
+}
+
+// newResult returns a pending result that clones its value on the way out.
+func (l *Dataloader[K, T]) newResult() *Result[T] {
+	return &Result[T]{clone: l.clone}
+}
@@ -0,0 +1,113 @@
+package dataloader_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+type account struct {
+	ID     string
+	Data   map[string]string
+	Tags   []string
+	Status *status
+	Any    any
+}
+
+type status struct {
+	Name string
+}
+
+func TestDeepCopy(t *testing.T) {
+	t.Parallel()
+
+	acc := account{
+		ID:     "1",
+		Data:   map[string]string{"id": "1"},
+		Tags:   []string{"a"},
+		Status: &status{Name: "pending"},
+		Any:    &status{Name: "any"},
+	}
+
+	clone := dataloader.DeepCopy(acc)
+	clone.Data["id"] = "2"
+	clone.Tags[0] = "b"
+	clone.Status.Name = "success"
+	clone.Any.(*status).Name = "mutated"
+
+	if exp, got := fmt.Sprint(map[string]string{"id": "1"}, []string{"a"}, "pending", "any"),
+		fmt.Sprint(acc.Data, acc.Tags, acc.Status.Name, acc.Any.(*status).Name); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	// Cycles are preserved.
+	type node struct {
+		Next *node
+	}
+
+	n := &node{}
+	n.Next = n
+
+	c := dataloader.DeepCopy(n)
+	if c == n || c.Next != c {
+		t.Fatal("expected cycle to be copied")
+	}
+
+	// Nil interfaces are copied as nil.
+	if err := dataloader.DeepCopy[error](nil); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+func TestWithDeepCopy(t *testing.T) {
+	t.Parallel()
+
+	fetchAccounts := func(ctx context.Context, keys []string) (map[string]account, error) {
+		res := make(map[string]account)
+		for _, key := range keys {
+			res[key] = account{
+				ID:     key,
+				Data:   map[string]string{"id": key},
+				Status: &status{Name: "pending"},
+			}
+		}
+
+		return res, nil
+	}
+
+	dl := dataloader.NewManual(context.Background(), fetchAccounts,
+		dataloader.WithDeepCopy[string, account](),
+	)
+
+	res := dl.Load("1")
+	dl.Dispatch()
+
+	acc, err := res.Unwrap()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	acc.Data["hello"] = "world"
+	acc.Status.Name = "success"
+
+	primed := account{ID: "2", Data: map[string]string{}}
+	dl.Prime("2", primed)
+	primed.Data["hello"] = "world"
+
+	for _, key := range []string{"1", "2"} {
+		acc, err := dl.Load(key).Wait()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if _, ok := acc.Data["hello"]; ok {
+			t.Fatalf("expected cached value of %s not to be mutated", key)
+		}
+	}
+
+	if acc, _ := dl.Load("1").Unwrap(); acc.Status.Name != "pending" {
+		t.Fatalf("expected pending, got %v", acc.Status.Name)
+	}
+}
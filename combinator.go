@@ -0,0 +1,167 @@
+package dataloader
+
+import "context"
+
+// Map returns a result that is resolved with fn applied to the value of r.
+func Map[T, U any](ctx context.Context, r *Result[T], fn func(T) U) *Result[U] {
+	return Then(ctx, r, func(t T) *Result[U] {
+		return Resolve(fn(t))
+	})
+}
+
+// Then chains a dependent load, for example loading the shipment of an
+// order once the order is loaded. A nil result from fn is rejected with
+// ErrNoResult.
+func Then[T, U any](ctx context.Context, r *Result[T], fn func(T) *Result[U]) *Result[U] {
+	out := new(Result[U])
+
+	go func() {
+		t, err := r.WaitContext(ctx)
+		if err != nil {
+			out.reject(err)
+
+			return
+		}
+
+		next := fn(t)
+		if next == nil {
+			out.reject(ErrNoResult)
+
+			return
+		}
+
+		u, err := next.WaitContext(ctx)
+		if err != nil {
+			out.reject(err)
+
+			return
+		}
+
+		out.resolve(u)
+	}()
+
+	return out
+}
+
+// All returns a result that is resolved with the values of all the results,
+// in the same order, or rejected with the first error.
+func All[T any](ctx context.Context, results ...*Result[T]) *Result[[]T] {
+	out := new(Result[[]T])
+
+	go func() {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		values := make([]T, len(results))
+		settled := settle(ctx, results)
+
+		for range results {
+			select {
+			case <-ctx.Done():
+				out.reject(ctx.Err())
+
+				return
+			case i := <-settled:
+				t, err := results[i].Unwrap()
+				if err != nil {
+					out.reject(err)
+
+					return
+				}
+
+				values[i] = t
+			}
+		}
+
+		out.resolve(values)
+	}()
+
+	return out
+}
+
+// Any returns a result that is resolved with the first value. If all the
+// results are rejected, it is rejected with the error of the first result.
+func Any[T any](ctx context.Context, results ...*Result[T]) *Result[T] {
+	out := new(Result[T])
+
+	go func() {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		settled := settle(ctx, results)
+
+		for range results {
+			select {
+			case <-ctx.Done():
+				out.reject(ctx.Err())
+
+				return
+			case i := <-settled:
+				t, err := results[i].Unwrap()
+				if err == nil {
+					out.resolve(t)
+
+					return
+				}
+			}
+		}
+
+		if len(results) == 0 {
+			out.reject(ErrNoResult)
+
+			return
+		}
+
+		out.reject(results[0].Error())
+	}()
+
+	return out
+}
+
+// Race returns a result that is settled like the first result to settle.
+func Race[T any](ctx context.Context, results ...*Result[T]) *Result[T] {
+	out := new(Result[T])
+
+	go func() {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		if len(results) == 0 {
+			out.reject(ErrNoResult)
+
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			out.reject(ctx.Err())
+		case i := <-settle(ctx, results):
+			t, err := results[i].Unwrap()
+			if err != nil {
+				out.reject(err)
+			} else {
+				out.resolve(t)
+			}
+		}
+	}()
+
+	return out
+}
+
+// settle sends the index of each result as it is settled, until the context
+// is done.
+func settle[T any](ctx context.Context, results []*Result[T]) <-chan int {
+	ch := make(chan int, len(results))
+
+	for i, r := range results {
+		go func(i int, r *Result[T]) {
+			select {
+			case <-ctx.Done():
+			case <-r.Done():
+				ch <- i
+			}
+		}(i, r)
+	}
+
+	return ch
+}
@@ -0,0 +1,106 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func TestCombinators(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	wantErr := errors.New("want error")
+
+	t.Run("map", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := dataloader.Map(ctx, dataloader.Resolve(42), strconv.Itoa).Wait()
+		if err != nil || res != "42" {
+			t.Fatalf("expected 42, got %v, %v", res, err)
+		}
+
+		_, err = dataloader.Map(ctx, dataloader.Reject[int](wantErr), strconv.Itoa).Wait()
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("expected %v, got %v", wantErr, err)
+		}
+	})
+
+	t.Run("then", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := dataloader.Then(ctx, dataloader.Resolve(1), func(n int) *dataloader.Result[string] {
+			return dataloader.Resolve(fmt.Sprint(n + 1))
+		}).Wait()
+		if err != nil || res != "2" {
+			t.Fatalf("expected 2, got %v, %v", res, err)
+		}
+
+		_, err = dataloader.Then(ctx, dataloader.Resolve(1), func(n int) *dataloader.Result[string] {
+			return nil
+		}).Wait()
+		if !errors.Is(err, dataloader.ErrNoResult) {
+			t.Fatalf("expected %v, got %v", dataloader.ErrNoResult, err)
+		}
+	})
+
+	t.Run("all", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := dataloader.All(ctx, dataloader.Resolve(1), dataloader.Resolve(2)).Wait()
+		if exp, got := "[1 2]", fmt.Sprint(res); err != nil || exp != got {
+			t.Fatalf("expected %v, got %v, %v", exp, got, err)
+		}
+
+		_, err = dataloader.All(ctx, dataloader.Resolve(1), dataloader.Reject[int](wantErr), new(dataloader.Result[int])).Wait()
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("expected %v, got %v", wantErr, err)
+		}
+	})
+
+	t.Run("any", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := dataloader.Any(ctx, dataloader.Reject[int](wantErr), dataloader.Resolve(2)).Wait()
+		if err != nil || res != 2 {
+			t.Fatalf("expected 2, got %v, %v", res, err)
+		}
+
+		_, err = dataloader.Any(ctx, dataloader.Reject[int](wantErr), dataloader.Reject[int](errors.New("other"))).Wait()
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("expected %v, got %v", wantErr, err)
+		}
+	})
+
+	t.Run("race", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := dataloader.Race(ctx, new(dataloader.Result[int]), dataloader.Reject[int](wantErr)).Wait()
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("expected %v, got %v", wantErr, err)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		pending := new(dataloader.Result[int])
+
+		_, err := dataloader.All(ctx, pending).Wait()
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected %v, got %v", context.Canceled, err)
+		}
+
+		_, err = dataloader.Map(ctx, pending, strconv.Itoa).Wait()
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected %v, got %v", context.Canceled, err)
+		}
+	})
+}
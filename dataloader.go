@@ -2,22 +2,63 @@ package dataloader
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const defaultBatchDuration = 16 * time.Millisecond
 
 type Dataloader[K comparable, T any] struct {
-	ch   chan K
-	cond sync.Cond
+	// Number of batches dispatched by the background goroutine. Kept first
+	// for 64-bit alignment of atomic operations.
+	inflight int64
+	stats    stats
+
+	mu   sync.Mutex
 	ctx  context.Context
 	data map[K]*Result[T]
 	done chan bool
-	init sync.Once
 	wg   sync.WaitGroup
 
+	// Results of the keys waiting to be batched.
+	pending map[K]*Result[T]
+
+	// Keys not yet received by the background goroutine, which is woken up
+	// without blocking the caller.
+	queue []K
+	wake  chan struct{}
+
+	// When the cached results were settled, and the stale results that are
+	// being refreshed. Only tracked when the cache expires.
+	loadedAt   map[K]time.Time
+	refreshing map[K]refresh[T]
+
+	// How long until a cached result is stale, and expired.
+	staleAfter time.Duration
+	ttl        time.Duration
+
+	// How often the cached keys are loaded, and when they were last scanned
+	// for refresh-ahead.
+	hits            map[K]int
+	refreshAheadCfg *RefreshAhead
+	lastScan        time.Time
+
+	// Whether the background goroutine is running, and whether it settles
+	// the collected keys when it stops, instead of rejecting them.
+	started  bool
+	draining bool
+
+	// The batches dispatched by the background goroutine.
+	batches sync.WaitGroup
+
+	// How long the background goroutine stays idle before it stops.
+	idleTimeout time.Duration
+
+	clock Clock
+
 	// How many keys gathered before the batchFn executes.
 	batchMaxKeys int
 
@@ -26,246 +67,711 @@ type Dataloader[K comparable, T any] struct {
 
 	// How many concurrent batchFn is allowed to run.
 	batchMaxWorker chan struct{}
-	batchFn        BatchFunc[K, T]
+	batchFn        StreamingBatchFunc[K, T]
+
+	// How long the batchFn is allowed to run.
+	batchTimeout time.Duration
+
+	// How many times a failed batch is split in halves and retried.
+	bisectDepth int
+
+	autoPrimeCfg *AutoPrime[K, T]
+
+	// Called with the values returned by the batchFn.
+	listeners []func(T)
+
+	// Derives the context of a batch once, before the batchFn is called,
+	// retried or hedged.
+	batchContext func(ctx context.Context, keys []K) context.Context
+
+	// Clones the values on the way in and out of the cache.
+	clone func(T) T
+
+	breaker *breaker
+	limiter *limiter
+	hedger  *hedger[K, T]
+
+	observers []func(Event[K])
+
+	// Batches never mix keys of different partitions.
+	partitionFn        func(K) any
+	partitionMaxWorker int
+	partitionWorkers   map[any]*partitionWorker
+
+	// Signalled when a partition worker is freed.
+	partitionFreed *sync.Cond
 }
 
 type BatchFunc[K comparable, T any] func(ctx context.Context, keys []K) (map[K]T, error)
 
+// StreamingBatchFunc emits the result of each key as soon as it is available,
+// waking the waiters of that key immediately. Keys that are not emitted
+// when it returns are rejected with the returned error, or ErrKeyNotFound.
+type StreamingBatchFunc[K comparable, T any] func(ctx context.Context, keys []K, emit func(key K, value T, err error)) error
+
 func New[K comparable, T any](ctx context.Context, batchFn BatchFunc[K, T], options ...Option[K, T]) (*Dataloader[K, T], func()) {
+	return NewStreaming(ctx, batchFn.stream(), options...)
+}
+
+func NewStreaming[K comparable, T any](ctx context.Context, batchFn StreamingBatchFunc[K, T], options ...Option[K, T]) (*Dataloader[K, T], func()) {
 	dataloader := &Dataloader[K, T]{
-		data:           make(map[K]*Result[T]),
-		cond:           sync.Cond{L: &sync.Mutex{}},
-		done:           make(chan bool),
-		ch:             make(chan K),
-		ctx:            ctx,
-		batchDuration:  defaultBatchDuration,
-		batchMaxKeys:   0,
-		batchMaxWorker: make(chan struct{}, 1),
-		batchFn:        batchFn,
+		data:             make(map[K]*Result[T]),
+		pending:          make(map[K]*Result[T]),
+		loadedAt:         make(map[K]time.Time),
+		refreshing:       make(map[K]refresh[T]),
+		hits:             make(map[K]int),
+		partitionWorkers: make(map[any]*partitionWorker),
+		done:             make(chan bool),
+		wake:             make(chan struct{}, 1),
+		ctx:              ctx,
+		batchDuration:    defaultBatchDuration,
+		batchMaxKeys:     0,
+		batchMaxWorker:   make(chan struct{}, 1),
+		batchFn:          batchFn,
+		clock:            realClock{},
 	}
 
+	dataloader.partitionFreed = sync.NewCond(&dataloader.mu)
+
 	for _, opt := range options {
 		opt(dataloader)
 	}
 
-	var once sync.Once
-	return dataloader, func() {
-		dataloader.init.Do(func() {})
+	return dataloader, dataloader.stop
+}
 
-		once.Do(func() {
-			close(dataloader.done)
-			dataloader.wg.Wait()
-		})
+// Reset dispatches the collected keys and waits for the in-flight batches to
+// settle, then clears the cache, closes the circuit and re-arms the loader,
+// so that it can be reused even after it has been flushed. Keys loaded while
+// it is draining are rejected with ErrTerminated.
+func (l *Dataloader[K, T]) Reset() {
+	l.mu.Lock()
+	l.draining = true
+	l.mu.Unlock()
+
+	l.stop()
+
+	l.mu.Lock()
+	l.draining = false
+	l.data = make(map[K]*Result[T])
+	l.pending = make(map[K]*Result[T])
+	l.loadedAt = make(map[K]time.Time)
+	l.refreshing = make(map[K]refresh[T])
+	l.hits = make(map[K]int)
+	l.queue = nil
+	l.done = make(chan bool)
+	l.started = false
+	l.mu.Unlock()
+
+	if l.breaker != nil && l.breaker.restart() {
+		l.notifyCircuit(CircuitClosed)
 	}
 }
 
 func (l *Dataloader[K, T]) Load(key K) (T, error) {
-	if res := l.load(key); !res.IsZero() {
-		return res.Unwrap()
+	return l.load(key).Wait()
+}
+
+// LoadThunk enqueues the key and returns immediately. The result can be
+// awaited later, so that many keys can be loaded before blocking.
+func (l *Dataloader[K, T]) LoadThunk(key K) *Result[T] {
+	return l.load(key)
+}
+
+func (l *Dataloader[K, T]) LoadMany(keys []K) (map[K]T, error) {
+	return l.waitMany(l.loadMany(keys))
+}
+
+// LoadManyThunk enqueues the keys and returns immediately with the eventual
+// result of all the keys.
+func (l *Dataloader[K, T]) LoadManyThunk(keys []K) *Result[map[K]T] {
+	thunks := l.loadMany(keys)
+
+	out := new(Result[map[K]T])
+	go func() {
+		result, err := l.waitMany(thunks)
+		if err != nil {
+			out.reject(err)
+
+			return
+		}
+
+		out.resolve(result)
+	}()
+
+	return out
+}
+
+// LoadManySlice returns the values in the same order as the keys, including
+// duplicate keys.
+func (l *Dataloader[K, T]) LoadManySlice(keys []K) ([]T, error) {
+	thunks := l.LoadManyResults(keys)
+
+	result := make([]T, len(thunks))
+	for i, res := range thunks {
+		t, err := res.Wait()
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = t
 	}
 
-	return l.wait(key).Unwrap()
+	return result, nil
 }
 
-func (l *Dataloader[K, T]) LoadThunk(key K) *Result[T] {
-	if res := l.load(key); !res.IsZero() {
-		return res
+// LoadManyResults returns the results in the same order as the keys,
+// without blocking.
+func (l *Dataloader[K, T]) LoadManyResults(keys []K) []*Result[T] {
+	thunks := make([]*Result[T], len(keys))
+	for i, key := range keys {
+		thunks[i] = l.load(key)
 	}
 
-	return l.wait(key)
+	return thunks
 }
 
-func (l *Dataloader[K, T]) LoadMany(keys []K) (map[K]T, error) {
-	result := make(map[K]T, len(keys))
+// Prime caches the value of the key. If the key is pending, its waiters
+// receive the primed value and the batch result is discarded. Otherwise the
+// cached result is overwritten.
+func (l *Dataloader[K, T]) Prime(key K, res T) {
+	l.mu.Lock()
+	l.prime(key, res, nil)
+	l.mu.Unlock()
+}
 
-	for _, key := range keys {
-		if res := l.load(key); !res.IsZero() {
-			t, err := res.Unwrap()
-			if err != nil {
-				return nil, err
-			}
+func (l *Dataloader[K, T]) PrimeMany(m map[K]T) {
+	l.mu.Lock()
+	for key, res := range m {
+		l.prime(key, res, nil)
+	}
+	l.mu.Unlock()
+}
 
-			result[key] = t
-		}
+// PrimeError caches the error of the key, with the same semantics as Prime.
+func (l *Dataloader[K, T]) PrimeError(key K, err error) {
+	var t T
+
+	l.mu.Lock()
+	l.prime(key, t, err)
+	l.mu.Unlock()
+}
+
+// PrimeIfAbsent caches the value only if the key is not cached or expired,
+// and reports whether it did. Pending keys are left untouched.
+func (l *Dataloader[K, T]) PrimeIfAbsent(key K, res T) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r, ok := l.data[key]; ok && l.freshness(key, r) != expired {
+		return false
 	}
 
+	l.prime(key, res, nil)
+
+	return true
+}
+
+// Replace overwrites the value only if the key is pending or cached, and
+// reports whether it did.
+func (l *Dataloader[K, T]) Replace(key K, res T) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.data[key]; !ok {
+		return false
+	}
+
+	l.prime(key, res, nil)
+
+	return true
+}
+
+// prime settles the key. A pending result is settled in place, so that its
+// waiters receive the primed value. It must be called with the lock held.
+func (l *Dataloader[K, T]) prime(key K, t T, err error) {
+	res, ok := l.data[key]
+	if !ok || res.Ready() {
+		res = l.newResult()
+		l.data[key] = res
+	}
+
+	// The batch has nothing left to settle.
+	if l.pending[key] == res {
+		delete(l.pending, key)
+	}
+
+	if err != nil {
+		res.reject(err)
+	} else if l.clone != nil {
+		res.resolve(l.clone(t))
+	} else {
+		res.resolve(t)
+	}
+
+	l.touch(key)
+}
+
+func (l *Dataloader[K, T]) loadMany(keys []K) map[K]*Result[T] {
+	thunks := make(map[K]*Result[T], len(keys))
 	for _, key := range keys {
-		_, ok := result[key]
-		if ok {
-			continue
+		if _, ok := thunks[key]; !ok {
+			thunks[key] = l.load(key)
 		}
+	}
 
-		res, err := l.wait(key).Unwrap()
+	return thunks
+}
+
+func (l *Dataloader[K, T]) waitMany(thunks map[K]*Result[T]) (map[K]T, error) {
+	result := make(map[K]T, len(thunks))
+	for key, res := range thunks {
+		t, err := res.Wait()
 		if err != nil {
 			return nil, err
 		}
 
-		result[key] = res
+		result[key] = t
 	}
 
 	return result, nil
 }
 
-func (l *Dataloader[K, T]) Prime(key K, res T) {
-	l.cond.L.Lock()
+func (l *Dataloader[K, T]) load(key K) *Result[T] {
+	return l.loadMiss(key, nil)
+}
 
-	l.data[key] = new(Result[T]).resolve(res)
+// loadMiss loads the key, and calls miss with the lock held when the key is
+// queued for the next batch.
+func (l *Dataloader[K, T]) loadMiss(key K, miss func()) *Result[T] {
+	l.mu.Lock()
+	res, next := l.lookup(key)
+	if next == nil {
+		l.mu.Unlock()
 
-	l.cond.L.Unlock()
-	l.cond.Broadcast()
-}
+		return res
+	}
+
+	if miss != nil {
+		miss()
+	}
 
-func (l *Dataloader[K, T]) wait(key K) *Result[T] {
-	l.cond.L.Lock()
-	for l.pending(key) {
-		l.cond.Wait()
+	if next == res {
+		return l.enqueue(key, next)
 	}
 
-	res := l.data[key]
-	l.cond.L.Unlock()
+	// Refresh the stale value in the background. enqueue does not block, so
+	// the stale value is returned immediately.
+	l.enqueue(key, next)
 
 	return res
 }
 
-func (l *Dataloader[K, T]) load(key K) *Result[T] {
-	l.init.Do(func() {
-		select {
-		case <-l.done:
-			return
-		default:
-			// Lazily create a background goroutine.
-			l.loopAsync()
-		}
-	})
+// enqueue queues the key for the background goroutine, so that the result
+// is settled in the next batch. It never waits for the background goroutine,
+// which may be busy waiting for a worker. It must be called with the lock
+// held, and releases it.
+func (l *Dataloader[K, T]) enqueue(key K, res *Result[T]) *Result[T] {
+	done := l.done
+	if isClosed(done) {
+		l.mu.Unlock()
 
-	l.cond.L.Lock()
-	res, found := l.data[key]
-	if !found {
-		l.data[key] = new(Result[T])
+		return res.reject(ErrTerminated)
 	}
-	l.cond.L.Unlock()
 
-	if found {
-		return res
+	l.pending[key] = res
+	l.queue = append(l.queue, key)
+	if !l.started {
+		// Lazily create a background goroutine.
+		l.started = true
+		l.loopAsync(done)
 	}
+	l.mu.Unlock()
 
-	// If it's not yet set, then set it.
-	// Otherwise, the fetching might not be completed yet.
-	select {
-	case <-l.done:
-		l.cond.L.Lock()
-		res = l.data[key].reject(ErrTerminated)
-		l.cond.L.Unlock()
+	l.notifyLoop()
 
-		return res
-	case l.ch <- key:
-		return nil
+	return res
+}
+
+// notifyLoop wakes up the background goroutine without blocking.
+func (l *Dataloader[K, T]) notifyLoop() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
 	}
 }
 
-func (l *Dataloader[K, T]) pending(key K) bool {
-	res, ok := l.data[key]
+// dequeue takes the keys queued for the background goroutine.
+func (l *Dataloader[K, T]) dequeue() []K {
+	l.mu.Lock()
+	keys := l.queue
+	l.queue = nil
+	l.mu.Unlock()
 
-	return !ok || res.IsZero()
+	return keys
 }
 
-func (l *Dataloader[K, T]) batch(ctx context.Context, keys []K) {
-	res, err := l.batchFn(ctx, keys)
+func (l *Dataloader[K, T]) stop() {
+	l.mu.Lock()
+	if !isClosed(l.done) {
+		close(l.done)
+	}
+	l.mu.Unlock()
 
-	l.cond.L.Lock()
+	l.wg.Wait()
+}
 
+func (l *Dataloader[K, T]) batch(ctx context.Context, keys []K) {
+	// Take the results to settle before calling the batchFn, as the cache may
+	// be primed in the meantime.
+	l.mu.Lock()
+	results := make(map[K]*Result[T], len(keys))
 	for _, key := range keys {
-		// If there's an error, set all results to the error.
-		// Otherwise, the sync.Cond will wait forever.
-		if err != nil {
-			l.data[key].reject(err)
+		if r, ok := l.pending[key]; ok {
+			results[key] = r
+			delete(l.pending, key)
+		}
+	}
+	listeners := l.listeners
+	l.mu.Unlock()
+
+	if l.batchContext != nil {
+		ctx = l.batchContext(ctx, keys)
+	}
 
-			continue
+	emit := func(key K, val T, err error) {
+		if err == nil {
+			for _, fn := range listeners {
+				fn(val)
+			}
 		}
 
-		val, ok := res[key]
+		r, ok := results[key]
 		if !ok {
-			l.data[key].reject(fmt.Errorf("%w: %v", ErrKeyNotFound, key))
+			if err == nil {
+				l.autoPrime(key, val)
+			}
+
+			return
+		}
+
+		if err != nil {
+			r.reject(err)
 		} else {
-			l.data[key].resolve(val)
+			r.resolve(val)
 		}
 	}
 
-	l.cond.L.Unlock()
-	l.cond.Broadcast()
+	l.guard(ctx, keys, results, emit)
+	l.settled(results)
 }
 
-func (l *Dataloader[K, T]) batchAsync(ctx context.Context, keys []K) {
+// call runs the batchFn and settles the remaining results. When bisection is
+// enabled, a failed batch is split into halves that are retried recursively,
+// so that only the keys that break the batch are rejected. It returns the
+// error of the first call.
+func (l *Dataloader[K, T]) call(ctx context.Context, keys []K, results map[K]*Result[T], emit func(K, T, error), depth int) error {
+	err := l.invoke(ctx, keys, emit)
+
+	var unsettled []K
+	for _, key := range keys {
+		if r, ok := results[key]; ok && !r.Ready() {
+			unsettled = append(unsettled, key)
+		}
+	}
+
+	if err == nil {
+		for _, key := range unsettled {
+			results[key].reject(fmt.Errorf("%w: %v", ErrKeyNotFound, key))
+		}
+
+		return nil
+	}
+
+	// A timed out batch is not retried, so that its waiters are released
+	// when the timeout expires.
+	if len(unsettled) > 1 && depth < l.bisectDepth && ctx.Err() == nil && !errors.Is(err, ErrBatchTimeout) {
+		mid := len(unsettled) / 2
+		l.call(ctx, unsettled[:mid], results, emit, depth+1)
+		l.call(ctx, unsettled[mid:], results, emit, depth+1)
+
+		return err
+	}
+
+	// If there's an error, set all remaining results to the error.
+	// Otherwise, the waiters will wait forever.
+	for _, key := range unsettled {
+		results[key].reject(err)
+	}
+
+	if depth > 0 && len(unsettled) > 0 {
+		l.notify(Event[K]{
+			Type: EventBisect,
+			Keys: unsettled,
+			Err:  err,
+		})
+	}
+
+	return err
+}
+
+// invoke runs the batchFn once, guarded by the rate limiter.
+func (l *Dataloader[K, T]) invoke(ctx context.Context, keys []K, emit func(K, T, error)) error {
+	if err := l.wait(ctx, keys); err != nil {
+		return err
+	}
+
+	return l.invokeTimeout(ctx, keys, emit)
+}
+
+// invokeTimeout runs the batchFn. With a batch timeout, the waiters are
+// released when the timeout expires, even if the batchFn ignores the context,
+// and the late results are discarded.
+func (l *Dataloader[K, T]) invokeTimeout(ctx context.Context, keys []K, emit func(K, T, error)) error {
+	if l.batchTimeout <= 0 {
+		return l.hedge(ctx, keys, emit)
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, l.batchTimeout)
+	defer cancel()
+
+	timer := l.clock.NewTimer(l.batchTimeout)
+	defer timer.Stop()
+
+	var mu sync.Mutex
+	var expired bool
+
+	ch := make(chan error, 1)
+	go func() {
+		ch <- l.hedge(ctx, keys, func(key K, val T, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if !expired {
+				emit(key, val, err)
+			}
+		})
+	}()
+
+	select {
+	case err := <-ch:
+		return err
+	case <-ctx.Done():
+	case <-timer.C():
+	}
+
+	mu.Lock()
+	expired = true
+	mu.Unlock()
+
+	if err := parent.Err(); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: %v", ErrBatchTimeout, l.batchTimeout)
+}
+
+func (fn BatchFunc[K, T]) stream() StreamingBatchFunc[K, T] {
+	return func(ctx context.Context, keys []K, emit func(K, T, error)) error {
+		res, err := fn(ctx, keys)
+		if err != nil {
+			return err
+		}
+
+		for key, val := range res {
+			emit(key, val, nil)
+		}
+
+		return nil
+	}
+}
+
+// batchAsync runs the batch in a new goroutine once a worker is free. It
+// reports false without waiting when the partition of the keys is busy, so
+// that the keys of the other partitions are not held up.
+func (l *Dataloader[K, T]) batchAsync(ctx context.Context, keys []K) bool {
 	if len(keys) == 0 {
-		return
+		return true
+	}
+
+	if !l.acquirePartition(keys) {
+		return false
 	}
 
 	l.wg.Add(1)
+	l.batches.Add(1)
+	atomic.AddInt64(&l.inflight, 1)
 	l.batchMaxWorker <- struct{}{}
 
 	go func(keys []K) {
 		defer func() {
 			<-l.batchMaxWorker
+			l.releasePartition(keys)
+			atomic.AddInt64(&l.inflight, -1)
+			l.batches.Done()
 			l.wg.Done()
 		}()
 
 		l.batch(ctx, keys)
 	}(keys)
+
+	return true
 }
 
-func (l *Dataloader[K, T]) loop() {
-	ticker := time.NewTicker(l.batchDuration)
+func (l *Dataloader[K, T]) loop(done chan bool, ticker Ticker) {
 	defer ticker.Stop()
 
 	ctx, cancel := context.WithCancel(l.ctx)
 	defer cancel()
 
-	keys := make([]K, 0, l.batchMaxKeys)
+	// The collected keys by partition. The keys of a busy partition wait
+	// here for a free worker.
+	keys := make(map[any][]K)
+	lastActive := l.clock.Now()
+
+	// dispatch batches the keys of the partition. Unless all is set, only
+	// full batches are dispatched, and the other keys wait for more keys.
+	dispatch := func(p any, all bool) {
+		batches := chunk(keys[p], l.batchMaxKeys)
+
+		n := 0
+		for _, batch := range batches {
+			full := l.batchMaxKeys > 0 && len(batch) == l.batchMaxKeys
+			if (!all && !full) || !l.batchAsync(ctx, batch) {
+				break
+			}
+			n++
+		}
+
+		switch n {
+		case 0:
+		case len(batches):
+			delete(keys, p)
+		default:
+			var rest []K
+			for _, batch := range batches[n:] {
+				rest = append(rest, batch...)
+			}
+			keys[p] = rest
+		}
+	}
+
+	add := func(batch ...K) {
+		l.group(keys, batch)
+
+		// Full batches do not wait for the ticker. This also retries the
+		// partitions that were busy.
+		for p := range keys {
+			dispatch(p, false)
+		}
+	}
 
 	for {
 		select {
-		case <-l.done:
-			l.cond.L.Lock()
-
-			for _, key := range keys {
-				_, found := l.data[key]
-				if found {
-					l.data[key].reject(ErrTerminated)
-					continue
+		case <-done:
+			l.mu.Lock()
+			draining := l.draining
+			l.mu.Unlock()
+
+			if draining {
+				// Settle the collected keys before the batch context is
+				// cancelled. Busy partitions are retried once the
+				// dispatched batches are done.
+				add(l.dequeue()...)
+				for len(keys) > 0 {
+					for p := range keys {
+						dispatch(p, true)
+					}
+					l.batches.Wait()
 				}
-				l.data[key] = new(Result[T]).reject(ErrTerminated)
+				l.batches.Wait()
+
+				return
 			}
 
+			l.mu.Lock()
+
+			for key := range l.pending {
+				l.pending[key].reject(ErrTerminated)
+				delete(l.pending, key)
+			}
+			l.queue = nil
+
 			for key := range l.data {
 				l.data[key].reject(ErrTerminated)
 			}
 
-			l.cond.L.Unlock()
-			l.cond.Broadcast()
+			l.mu.Unlock()
 
 			return
-		case <-ticker.C:
-			l.batchAsync(ctx, keys)
-			keys = nil
-		case key := <-l.ch:
-			ticker.Reset(l.batchDuration)
-
-			keys = append(keys, key)
-			if l.batchMaxKeys == 0 || len(keys) < l.batchMaxKeys {
-				continue
+		case <-ticker.C():
+			// Hot keys that are about to expire are batched with the other
+			// keys.
+			add(l.dequeue()...)
+			add(l.refreshAhead()...)
+
+			if len(keys) > 0 || atomic.LoadInt64(&l.inflight) > 0 {
+				lastActive = l.clock.Now()
+			} else if l.idle(lastActive) {
+				return
+			}
+
+			for p := range keys {
+				dispatch(p, true)
+			}
+		case <-l.wake:
+			// Also woken up when a partition worker is freed.
+			queued := l.dequeue()
+			if len(queued) > 0 {
+				ticker.Reset(l.batchDuration)
+				lastActive = l.clock.Now()
 			}
 
-			l.batchAsync(ctx, keys)
-			keys = nil
+			add(queued...)
+			add(l.refreshAhead()...)
 		}
 	}
 }
 
-func (l *Dataloader[K, T]) loopAsync() {
+// idle stops the background goroutine when it has been inactive for longer
+// than the idle timeout. The next load starts it again.
+func (l *Dataloader[K, T]) idle(lastActive time.Time) bool {
+	if l.idleTimeout <= 0 || l.clock.Now().Sub(lastActive) < l.idleTimeout {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Keys queued since the last wake up.
+	if len(l.queue) > 0 {
+		return false
+	}
+
+	l.started = false
+
+	return true
+}
+
+func (l *Dataloader[K, T]) loopAsync(done chan bool) {
 	l.wg.Add(1)
 
+	// The ticker starts with the first key, not when the goroutine is
+	// scheduled.
+	ticker := l.clock.NewTicker(l.batchDuration)
+
 	go func() {
 		defer l.wg.Done()
-		l.loop()
+		l.loop(done, ticker)
 	}()
 }
+
+func isClosed(done chan bool) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
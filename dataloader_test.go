@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/alextanhongpin/dataloader"
 )
@@ -40,6 +44,31 @@ func TestLoad(t *testing.T) {
 		}
 	})
 
+	t.Run("load thunk without blocking", func(t *testing.T) {
+		t.Parallel()
+
+		res := dl.LoadThunk(43)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := res.WaitContext(ctx)
+		if !errors.Is(err, context.Canceled) && !res.Ready() {
+			t.Fatalf("expected %v, got %v", context.Canceled, err)
+		}
+
+		<-res.Done()
+
+		val, err := res.Unwrap()
+		if err != nil {
+			t.FailNow()
+		}
+
+		if exp, got := "43", val; exp != got {
+			t.Fatalf("expected %s, got %s", exp, got)
+		}
+	})
+
 	t.Run("load once", func(t *testing.T) {
 		t.Parallel()
 
@@ -73,6 +102,32 @@ func TestLoad(t *testing.T) {
 		}
 	})
 
+	t.Run("load many thunk", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := dl.LoadManyThunk([]int{6, 7, 6}).Wait()
+		if err != nil {
+			t.FailNow()
+		}
+
+		if exp, got := fmt.Sprint(map[int]string{6: "6", 7: "7"}), fmt.Sprint(res); exp != got {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	})
+
+	t.Run("load many slice", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := dl.LoadManySlice([]int{9, 8, 9, 7})
+		if err != nil {
+			t.FailNow()
+		}
+
+		if exp, got := fmt.Sprint([]string{"9", "8", "9", "7"}), fmt.Sprint(res); exp != got {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	})
+
 	t.Run("primed", func(t *testing.T) {
 		t.Parallel()
 
@@ -97,6 +152,54 @@ func TestLoad(t *testing.T) {
 	})
 }
 
+func TestLoadThunkBusyWorker(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		<-release
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	dl, flush := dataloader.New(context.Background(), fetchNumber,
+		dataloader.WithBatchMaxKeys[int, string](1),
+	)
+	t.Cleanup(flush)
+
+	// The only worker is busy with the first key, and the background
+	// goroutine waits for it, but loading does not.
+	loaded := make(chan []*dataloader.Result[string])
+	go func() {
+		loaded <- []*dataloader.Result[string]{dl.LoadThunk(1), dl.LoadThunk(2), dl.LoadThunk(3)}
+	}()
+
+	var results []*dataloader.Result[string]
+	select {
+	case results = <-loaded:
+	case <-time.After(time.Second):
+		t.Fatal("expected LoadThunk not to block")
+	}
+
+	close(release)
+
+	for i, res := range results {
+		val, err := res.Wait()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if exp, got := fmt.Sprint(i+1), val; exp != got {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	}
+}
+
 func TestFlush(t *testing.T) {
 	t.Parallel()
 
@@ -172,3 +275,443 @@ func TestMissingKey(t *testing.T) {
 		}
 	})
 }
+
+func TestReset(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		atomic.AddInt32(&calls, 1)
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	ctx := context.Background()
+
+	dl, flush := dataloader.New(ctx, fetchNumber)
+	t.Cleanup(flush)
+
+	dl.Prime(1, "one")
+	flush()
+
+	_, err := dl.Load(42)
+	if !errors.Is(err, dataloader.ErrTerminated) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrTerminated, err)
+	}
+
+	dl.Reset()
+
+	for _, key := range []int{1, 42} {
+		res, err := dl.Load(key)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if exp, got := fmt.Sprint(key), res; exp != got {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	}
+
+	if exp, got := int32(2), atomic.LoadInt32(&calls); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestResetDrain(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-release:
+		}
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	dl, flush := dataloader.New(context.Background(), fetchNumber,
+		dataloader.WithBatchMaxKeys[int, string](1),
+	)
+	t.Cleanup(flush)
+
+	// The first batch is in flight, and the second key waits for the worker.
+	running := dl.LoadThunk(1)
+	<-started
+	queued := dl.LoadThunk(2)
+
+	reset := make(chan struct{})
+	go func() {
+		defer close(reset)
+
+		dl.Reset()
+	}()
+
+	// Reset waits for the batches instead of cancelling them.
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	<-reset
+
+	for i, res := range []*dataloader.Result[string]{running, queued} {
+		val, err := res.Wait()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if exp, got := fmt.Sprint(i+1), val; exp != got {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	}
+
+	if val, err := dl.Load(3); err != nil || val != "3" {
+		t.Fatalf("expected 3, got %v, %v", val, err)
+	}
+}
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		atomic.AddInt32(&calls, 1)
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	pool := dataloader.NewPool(context.Background(), fetchNumber)
+
+	for i := 0; i < 3; i++ {
+		dl := pool.Get()
+
+		res, err := dl.Load(42)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if exp, got := "42", res; exp != got {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+
+		pool.Put(dl)
+	}
+
+	if exp, got := int32(3), atomic.LoadInt32(&calls); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		res := make(map[int]string)
+
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	ctx := context.Background()
+	clock := &tickerClock{FakeClock: dataloader.NewFakeClock(time.Unix(0, 0))}
+
+	dl, flush := dataloader.New(ctx, fetchNumber,
+		dataloader.WithClock[int, string](clock),
+		dataloader.WithBatchDuration[int, string](time.Millisecond),
+		dataloader.WithIdleTimeout[int, string](5*time.Millisecond),
+	)
+	t.Cleanup(flush)
+
+	for i := 0; i < 3; i++ {
+		res := dl.LoadThunk(i)
+		for !res.Ready() {
+			clock.Advance(time.Millisecond)
+			runtime.Gosched()
+		}
+
+		val, err := res.Wait()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if exp, got := fmt.Sprint(i), val; exp != got {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+
+		// The background goroutine stops its ticker and exits once idle, and
+		// is started again by the next load.
+		for n := 0; clock.running() > 0; n++ {
+			if n == 1000 {
+				t.Fatal("expected the background goroutine to stop")
+			}
+
+			clock.Advance(time.Millisecond)
+			runtime.Gosched()
+		}
+
+		if exp, got := i+1, clock.started(); exp != got {
+			t.Fatalf("expected %v tickers, got %v", exp, got)
+		}
+	}
+
+	flush()
+
+	_, err := dl.Load(100)
+	if !errors.Is(err, dataloader.ErrTerminated) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrTerminated, err)
+	}
+}
+
+// tickerClock counts the tickers that are started and stopped.
+type tickerClock struct {
+	*dataloader.FakeClock
+
+	mu      sync.Mutex
+	tickers int
+	stopped int
+}
+
+func (c *tickerClock) NewTicker(d time.Duration) dataloader.Ticker {
+	c.mu.Lock()
+	c.tickers++
+	c.mu.Unlock()
+
+	return &countedTicker{Ticker: c.FakeClock.NewTicker(d), clock: c}
+}
+
+func (c *tickerClock) started() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tickers
+}
+
+func (c *tickerClock) running() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tickers - c.stopped
+}
+
+type countedTicker struct {
+	dataloader.Ticker
+	clock *tickerClock
+}
+
+func (t *countedTicker) Stop() {
+	t.Ticker.Stop()
+
+	t.clock.mu.Lock()
+	t.clock.stopped++
+	t.clock.mu.Unlock()
+}
+
+func TestLoadStream(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan bool)
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		// The batch of the second key is held back.
+		if keys[0] == 2 {
+			<-release
+		}
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	ctx := context.Background()
+
+	dl, flush := dataloader.New(ctx, fetchNumber,
+		dataloader.WithBatchMaxKeys[int, string](1),
+		dataloader.WithBatchMaxWorker[int, string](2),
+	)
+	t.Cleanup(flush)
+
+	ch := dl.LoadStream(ctx, []int{1, 2, 1})
+
+	entry := <-ch
+	if exp, got := (dataloader.Entry[int, string]{Key: 1, Value: "1"}), entry; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	close(release)
+
+	entry = <-ch
+	if exp, got := (dataloader.Entry[int, string]{Key: 2, Value: "2"}), entry; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if _, ok := <-ch; ok {
+		t.Fatal("expected channel to be closed")
+	}
+}
+
+// Not parallel, as it counts the goroutines.
+func TestLoadStreamGoroutines(t *testing.T) {
+	release := make(chan bool)
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		<-release
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dl, flush := dataloader.New(ctx, fetchNumber)
+	t.Cleanup(flush)
+
+	dl.Prime(0, "0")
+
+	before := runtime.NumGoroutine()
+
+	keys := make([]int, 1000)
+	for i := range keys {
+		keys[i] = i
+	}
+	ch := dl.LoadStream(ctx, keys)
+
+	// The entries do not wait in a goroutine each.
+	if n := runtime.NumGoroutine() - before; n > 10 {
+		t.Fatalf("expected at most 10 new goroutines, got %v", n)
+	}
+
+	// The primed key is sent without waiting for the batch.
+	if exp, got := (dataloader.Entry[int, string]{Key: 0, Value: "0"}), <-ch; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	close(release)
+
+	var n int
+	for entry := range ch {
+		if entry.Err != nil {
+			t.Fatalf("expected no error, got %v", entry.Err)
+		}
+		n++
+	}
+
+	if exp, got := len(keys)-1, n; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	t.Parallel()
+
+	var once sync.Once
+	emitted := make(chan bool)
+	release := make(chan bool)
+	wantErr := errors.New("want error")
+
+	fetchNumber := func(ctx context.Context, keys []int, emit func(int, string, error)) error {
+		emit(1, "1", nil)
+		emit(2, "", wantErr)
+		once.Do(func() {
+			close(emitted)
+		})
+
+		<-release
+
+		return nil
+	}
+
+	ctx := context.Background()
+
+	dl, flush := dataloader.NewStreaming(ctx, fetchNumber)
+	t.Cleanup(flush)
+
+	results := dl.LoadManyResults([]int{1, 2, 3})
+	<-emitted
+
+	// The emitted keys are settled before the batch completes.
+	if res, err := results[0].Wait(); err != nil || res != "1" {
+		t.Fatalf("expected 1, got %v, %v", res, err)
+	}
+
+	if _, err := results[1].Wait(); !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+
+	if results[2].Ready() {
+		t.Fatal("expected result to be pending")
+	}
+
+	close(release)
+
+	if _, err := results[2].Wait(); !errors.Is(err, dataloader.ErrKeyNotFound) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrKeyNotFound, err)
+	}
+}
+
+func TestBatchTimeout(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan bool)
+	returned := make(chan bool)
+	fetchNumber := func(ctx context.Context, keys []int, emit func(int, string, error)) error {
+		defer close(returned)
+
+		// Ignores the context.
+		<-release
+
+		for _, key := range keys {
+			emit(key, fmt.Sprint(key), nil)
+		}
+
+		return nil
+	}
+
+	ctx := context.Background()
+
+	dl, flush := dataloader.NewStreaming(ctx, fetchNumber,
+		dataloader.WithBatchTimeout[int, string](10*time.Millisecond),
+	)
+	t.Cleanup(flush)
+
+	_, err := dl.Load(42)
+	if !errors.Is(err, dataloader.ErrBatchTimeout) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrBatchTimeout, err)
+	}
+
+	close(release)
+	<-returned
+
+	// The late result is discarded.
+	_, err = dl.Load(42)
+	if !errors.Is(err, dataloader.ErrBatchTimeout) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrBatchTimeout, err)
+	}
+}
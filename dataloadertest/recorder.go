@@ -0,0 +1,238 @@
+// Package dataloadertest provides utilities for testing code that uses
+// dataloaders.
+package dataloadertest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+// Batch is a single call recorded by the Recorder.
+type Batch[K comparable] struct {
+	Ctx      context.Context
+	Keys     []K
+	Start    time.Time
+	Duration time.Duration
+}
+
+// Recorder wraps a batch function and records every call made to it.
+type Recorder[K comparable, T any] struct {
+	mu      sync.Mutex
+	batchFn dataloader.BatchFunc[K, T]
+	batches []Batch[K]
+	latency time.Duration
+	err     error
+	missing map[K]bool
+	clock   dataloader.Clock
+}
+
+func NewRecorder[K comparable, T any](batchFn dataloader.BatchFunc[K, T]) *Recorder[K, T] {
+	return &Recorder[K, T]{
+		batchFn: batchFn,
+		missing: make(map[K]bool),
+	}
+}
+
+// NewStaticRecorder returns a Recorder that serves the keys from the given
+// map.
+func NewStaticRecorder[K comparable, T any](data map[K]T) *Recorder[K, T] {
+	return NewRecorder(func(ctx context.Context, keys []K) (map[K]T, error) {
+		res := make(map[K]T, len(keys))
+		for _, key := range keys {
+			if val, ok := data[key]; ok {
+				res[key] = val
+			}
+		}
+
+		return res, nil
+	})
+}
+
+// WithLatency delays every batch by the given duration, or until the batch
+// context is done.
+func (r *Recorder[K, T]) WithLatency(latency time.Duration) *Recorder[K, T] {
+	r.mu.Lock()
+	r.latency = latency
+	r.mu.Unlock()
+
+	return r
+}
+
+// WithClock measures the batches and the latency with the given clock, e.g.
+// the dataloader.FakeClock passed to the dataloader.
+func (r *Recorder[K, T]) WithClock(clock dataloader.Clock) *Recorder[K, T] {
+	r.mu.Lock()
+	r.clock = clock
+	r.mu.Unlock()
+
+	return r
+}
+
+// WithError fails every batch with the given error. A nil error clears it.
+func (r *Recorder[K, T]) WithError(err error) *Recorder[K, T] {
+	r.mu.Lock()
+	r.err = err
+	r.mu.Unlock()
+
+	return r
+}
+
+// WithMissing removes the keys from every batch result.
+func (r *Recorder[K, T]) WithMissing(keys ...K) *Recorder[K, T] {
+	r.mu.Lock()
+	for _, key := range keys {
+		r.missing[key] = true
+	}
+	r.mu.Unlock()
+
+	return r
+}
+
+// BatchFunc is the dataloader.BatchFunc to pass to the dataloader.
+func (r *Recorder[K, T]) BatchFunc(ctx context.Context, keys []K) (map[K]T, error) {
+	r.mu.Lock()
+	latency, err, clock := r.latency, r.err, r.clock
+	r.mu.Unlock()
+
+	now := time.Now
+	if clock != nil {
+		now = clock.Now
+	}
+
+	start := now()
+	defer func() {
+		r.mu.Lock()
+		r.batches = append(r.batches, Batch[K]{
+			Ctx:      ctx,
+			Keys:     append([]K(nil), keys...),
+			Start:    start,
+			Duration: now().Sub(start),
+		})
+		r.mu.Unlock()
+	}()
+
+	if latency > 0 {
+		var elapsed <-chan time.Time
+		if clock != nil {
+			timer := clock.NewTimer(latency)
+			defer timer.Stop()
+
+			elapsed = timer.C()
+		} else {
+			timer := time.NewTimer(latency)
+			defer timer.Stop()
+
+			elapsed = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-elapsed:
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.batchFn(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.missing) == 0 {
+		return res, nil
+	}
+
+	// Copy the result, as the batchFn may return a shared map.
+	out := make(map[K]T, len(res))
+	for key, val := range res {
+		if !r.missing[key] {
+			out[key] = val
+		}
+	}
+
+	return out, nil
+}
+
+// Batches returns the recorded batches, in the order they completed.
+func (r *Recorder[K, T]) Batches() []Batch[K] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Batch[K](nil), r.batches...)
+}
+
+// Keys returns the keys of each recorded batch.
+func (r *Recorder[K, T]) Keys() [][]K {
+	batches := r.Batches()
+
+	keys := make([][]K, len(batches))
+	for i, b := range batches {
+		keys[i] = b.Keys
+	}
+
+	return keys
+}
+
+// Reset clears the recorded batches.
+func (r *Recorder[K, T]) Reset() {
+	r.mu.Lock()
+	r.batches = nil
+	r.mu.Unlock()
+}
+
+// AssertBatches fails the test if the batch function was not called exactly n
+// times.
+func (r *Recorder[K, T]) AssertBatches(t testing.TB, n int) {
+	t.Helper()
+
+	if exp, got := n, len(r.Batches()); exp != got {
+		t.Fatalf("expected %d batches, got %d: %v", exp, got, r.Keys())
+	}
+}
+
+// AssertKeys fails the test if the recorded batches do not match the given
+// keys. The order of the keys within a batch is ignored.
+func (r *Recorder[K, T]) AssertKeys(t testing.TB, keys ...[]K) {
+	t.Helper()
+
+	got := r.Keys()
+	if len(got) != len(keys) {
+		t.Fatalf("expected batches %v, got %v", keys, got)
+	}
+
+	for i := range keys {
+		if !sameKeys(keys[i], got[i]) {
+			t.Fatalf("expected batches %v, got %v", keys, got)
+		}
+	}
+}
+
+func sameKeys[K comparable](a, b []K) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	count := make(map[K]int, len(a))
+	for _, key := range a {
+		count[key]++
+	}
+
+	for _, key := range b {
+		count[key]--
+		if count[key] < 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,129 @@
+package dataloadertest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alextanhongpin/dataloader"
+	"github.com/alextanhongpin/dataloader/dataloadertest"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	rec := dataloadertest.NewStaticRecorder(map[int]string{
+		1: "one",
+		2: "two",
+		3: "three",
+	}).WithMissing(3)
+
+	dl := dataloader.NewManual(context.Background(), rec.BatchFunc)
+	results := dl.LoadMany([]int{1, 2, 3, 1})
+	dl.Dispatch()
+
+	rec.AssertBatches(t, 1)
+	rec.AssertKeys(t, []int{2, 3, 1})
+
+	if res, err := results[1].Unwrap(); err != nil || res != "one" {
+		t.Fatalf("expected one, got %v, %v", res, err)
+	}
+
+	if _, err := results[3].Unwrap(); !errors.Is(err, dataloader.ErrKeyNotFound) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrKeyNotFound, err)
+	}
+}
+
+func TestRecorderError(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("want error")
+	rec := dataloadertest.NewStaticRecorder(map[int]string{}).
+		WithError(wantErr).
+		WithLatency(10 * time.Millisecond)
+
+	_, err := rec.BatchFunc(context.Background(), []int{1})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = rec.BatchFunc(ctx, []int{2})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+
+	batches := rec.Batches()
+	if exp, got := 2, len(batches); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if batches[0].Duration < 10*time.Millisecond {
+		t.Fatalf("expected latency, got %v", batches[0].Duration)
+	}
+
+	if batches[1].Ctx != ctx {
+		t.Fatal("expected the batch context to be recorded")
+	}
+}
+
+func TestRecorderSharedMap(t *testing.T) {
+	t.Parallel()
+
+	fixture := map[int]string{1: "one", 2: "two"}
+	rec := dataloadertest.NewRecorder(func(ctx context.Context, keys []int) (map[int]string, error) {
+		return fixture, nil
+	}).WithMissing(2)
+
+	res, err := rec.BatchFunc(context.Background(), []int{1, 2})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, ok := res[2]; ok {
+		t.Fatal("expected key 2 to be missing")
+	}
+
+	// The map returned by the batch function is left untouched.
+	if exp, got := 2, len(fixture); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestRecorderClock(t *testing.T) {
+	t.Parallel()
+
+	clock := dataloader.NewFakeClock(time.Unix(0, 0))
+	rec := dataloadertest.NewStaticRecorder(map[int]string{1: "one"}).
+		WithClock(clock).
+		WithLatency(time.Hour)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		_, _ = rec.BatchFunc(context.Background(), []int{1})
+	}()
+
+	// The latency elapses when the clock is advanced, once the timer is set.
+	for {
+		select {
+		case <-done:
+			batches := rec.Batches()
+			if exp, got := 1970, batches[0].Start.UTC().Year(); exp != got {
+				t.Fatalf("expected %v, got %v", exp, got)
+			}
+
+			if batches[0].Duration < time.Hour {
+				t.Fatalf("expected latency, got %v", batches[0].Duration)
+			}
+
+			return
+		case <-time.After(time.Millisecond):
+			clock.Advance(time.Hour)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package dataloadertest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+var _ dataloader.Loader[int, any] = (*Static[int, any])(nil)
+
+// Call is a single method call recorded by Static.
+type Call[K comparable] struct {
+	Method string
+	Keys   []K
+}
+
+// Static is a map-backed dataloader.Loader that resolves every key
+// immediately, without batching. Missing keys are rejected with
+// dataloader.ErrKeyNotFound.
+type Static[K comparable, T any] struct {
+	mu    sync.Mutex
+	data  map[K]T
+	calls []Call[K]
+}
+
+func NewStatic[K comparable, T any](data map[K]T) *Static[K, T] {
+	s := &Static[K, T]{
+		data: make(map[K]T, len(data)),
+	}
+	for key, val := range data {
+		s.data[key] = val
+	}
+
+	return s
+}
+
+func (s *Static[K, T]) Load(key K) (T, error) {
+	s.record("Load", key)
+
+	return s.get(key)
+}
+
+func (s *Static[K, T]) LoadThunk(key K) *dataloader.Result[T] {
+	s.record("LoadThunk", key)
+
+	res, err := s.get(key)
+	if err != nil {
+		return dataloader.Reject[T](err)
+	}
+
+	return dataloader.Resolve(res)
+}
+
+func (s *Static[K, T]) LoadMany(keys []K) (map[K]T, error) {
+	s.record("LoadMany", keys...)
+
+	result := make(map[K]T, len(keys))
+	for _, key := range keys {
+		res, err := s.get(key)
+		if err != nil {
+			return nil, err
+		}
+
+		result[key] = res
+	}
+
+	return result, nil
+}
+
+func (s *Static[K, T]) Prime(key K, res T) {
+	s.record("Prime", key)
+
+	s.mu.Lock()
+	s.data[key] = res
+	s.mu.Unlock()
+}
+
+// Calls returns the recorded method calls, in order.
+func (s *Static[K, T]) Calls() []Call[K] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Call[K](nil), s.calls...)
+}
+
+func (s *Static[K, T]) get(key K) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, ok := s.data[key]
+	if !ok {
+		return res, fmt.Errorf("%w: %v", dataloader.ErrKeyNotFound, key)
+	}
+
+	return res, nil
+}
+
+func (s *Static[K, T]) record(method string, keys ...K) {
+	s.mu.Lock()
+	s.calls = append(s.calls, Call[K]{
+		Method: method,
+		Keys:   append([]K(nil), keys...),
+	})
+	s.mu.Unlock()
+}
@@ -0,0 +1,51 @@
+package dataloadertest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alextanhongpin/dataloader"
+	"github.com/alextanhongpin/dataloader/dataloadertest"
+)
+
+func TestStatic(t *testing.T) {
+	t.Parallel()
+
+	var dl dataloader.Loader[int, string] = dataloadertest.NewStatic(map[int]string{
+		1: "one",
+	})
+
+	dl.Prime(2, "two")
+
+	if res, err := dl.Load(2); err != nil || res != "two" {
+		t.Fatalf("expected two, got %v, %v", res, err)
+	}
+
+	if res, err := dl.LoadThunk(1).Unwrap(); err != nil || res != "one" {
+		t.Fatalf("expected one, got %v, %v", res, err)
+	}
+
+	keys := []int{1, 3}
+	if _, err := dl.LoadMany(keys); !errors.Is(err, dataloader.ErrKeyNotFound) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrKeyNotFound, err)
+	}
+
+	// The recorded keys are not aliased to the caller's slice.
+	keys[0] = 42
+
+	calls := dl.(*dataloadertest.Static[int, string]).Calls()
+
+	var methods []string
+	for _, call := range calls {
+		methods = append(methods, call.Method)
+	}
+
+	if exp, got := "[Prime Load LoadThunk LoadMany]", fmt.Sprint(methods); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if exp, got := "[1 3]", fmt.Sprint(calls[3].Keys); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
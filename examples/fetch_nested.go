@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
-	"sync"
 	"time"
 
 	"github.com/alextanhongpin/dataloader"
@@ -19,19 +18,15 @@ func main() {
 	defer flush()
 
 	fetchOrderAggregate := func(ctx context.Context, ids []string) (map[string]*OrderAggregate, error) {
-		result := make([]*OrderAggregate, len(ids))
-
-		var wg sync.WaitGroup
-		wg.Add(len(ids))
+		thunks := make([]*dataloader.Result[*OrderAggregate], len(ids))
 		for i, id := range ids {
-			go func(i int, id string) {
-				defer wg.Done()
-
-				result[i] = NewOrderAggregateResolver(loader, id).LoadAll().Wait()
-			}(i, id)
+			thunks[i] = LoadOrderAggregate(ctx, loader, id)
 		}
 
-		wg.Wait()
+		result, err := dataloader.All(ctx, thunks...).Wait()
+		if err != nil {
+			return nil, err
+		}
 
 		output := make(map[string]*OrderAggregate, len(result))
 		for i, id := range ids {
@@ -65,63 +60,30 @@ func main() {
 	}
 }
 
-type OrderAggregateResolver struct {
-	loader         *Loader
-	orderID        string
-	orderAggregate *OrderAggregate
-	wg             sync.WaitGroup
-}
+func LoadOrderAggregate(ctx context.Context, loader *Loader, orderID string) *dataloader.Result[*OrderAggregate] {
+	orderAggregate := new(OrderAggregate)
 
-func NewOrderAggregateResolver(loader *Loader, orderID string) *OrderAggregateResolver {
-	return &OrderAggregateResolver{
-		loader:         loader,
-		orderID:        orderID,
-		orderAggregate: new(OrderAggregate),
-	}
-}
-
-func (r *OrderAggregateResolver) LoadOrder() {
-	r.wg.Add(1)
+	order := dataloader.Then(ctx, loader.Order.LoadThunk(orderID), func(order Order) *dataloader.Result[Shipment] {
+		orderAggregate.Order = &order
 
-	go func() {
-		defer r.wg.Done()
+		return loader.Shipment.LoadThunk(order.ShipmentID)
+	})
 
-		if order, err := r.loader.Order.Load(r.orderID); err == nil {
-			r.orderAggregate.Order = &order
+	address := dataloader.Then(ctx, loader.Address.LoadThunk(orderID), func(address Address) *dataloader.Result[Country] {
+		orderAggregate.Address = &address
 
-			if shipment, err := r.loader.Shipment.Load(order.ShipmentID); err == nil {
-				r.orderAggregate.Shipment = &shipment
-			}
-		}
-	}()
-}
+		return loader.Country.LoadThunk(address.CountryID)
+	})
 
-func (r *OrderAggregateResolver) LoadShipment() {
-	r.wg.Add(1)
+	return dataloader.Then(ctx, order, func(shipment Shipment) *dataloader.Result[*OrderAggregate] {
+		orderAggregate.Shipment = &shipment
 
-	go func() {
-		defer r.wg.Done()
+		return dataloader.Map(ctx, address, func(country Country) *OrderAggregate {
+			orderAggregate.Address.Country = &country
 
-		if address, err := r.loader.Address.Load(r.orderID); err == nil {
-			r.orderAggregate.Address = &address
-
-			if country, err := r.loader.Country.Load(address.CountryID); err == nil {
-				r.orderAggregate.Address.Country = &country
-			}
-		}
-	}()
-}
-
-func (r *OrderAggregateResolver) LoadAll() *OrderAggregateResolver {
-	r.LoadOrder()
-	r.LoadShipment()
-	return r
-}
-
-func (r *OrderAggregateResolver) Wait() *OrderAggregate {
-	r.wg.Wait()
-
-	return r.orderAggregate
+			return orderAggregate
+		})
+	})
 }
 
 type Country struct {
@@ -217,10 +179,10 @@ func fetchCountries(ctx context.Context, keys []string) (map[string]Country, err
 }
 
 type Loader struct {
-	Order    *dataloader.Dataloader[string, Order]
-	Address  *dataloader.Dataloader[string, Address]
-	Shipment *dataloader.Dataloader[int64, Shipment]
-	Country  *dataloader.Dataloader[string, Country]
+	Order    dataloader.Loader[string, Order]
+	Address  dataloader.Loader[string, Address]
+	Shipment dataloader.Loader[int64, Shipment]
+	Country  dataloader.Loader[string, Country]
 }
 
 func NewLoader() (*Loader, func()) {
@@ -48,7 +48,13 @@ func fetchAccounts(ctx context.Context, keys []string) (map[string]Account, erro
 
 func main() {
 	ctx := context.Background()
-	dl, flush := dataloader.New(ctx, fetchAccounts)
+	dl, flush := dataloader.New(ctx, fetchAccounts,
+		// Without cloning, mutating the loaded account below corrupts the
+		// cached account for every later load.
+		// Alternatively, use dataloader.WithClone(Account.Clone), though it
+		// does not clone the Status pointer.
+		dataloader.WithDeepCopy[string, Account](),
+	)
 	defer flush()
 
 	fmt.Println("fetch 1")
@@ -60,9 +66,7 @@ func main() {
 
 	account.ID = "override-id"
 	account.Data["hello"] = "world"
-	// Better practice: to avoid the map being mutated, perform a deep clone.
-	//account = account.Clone()
-	account.Status = &Status{Name: "success"}
+	account.Status.Name = "success"
 
 	fmt.Println()
 	fmt.Println("fetch 2")
@@ -81,13 +85,9 @@ func main() {
 	}
 	fmt.Println("success:", account, *account.Status)
 
-	// This library does not protect against mutation of reference object, due to
-	// the way caching is implemented.
-	// Deep-clone the result.Data() to protect against mutation if the same key is fetched.
-
 	// Output:
-	// success &{account-1 map[id:account-1]}
-	// success &{account-1 map[hello:world]}
-	// success &{account-1 map[hello:world]}
+	// success: {account-1 map[id:account-1] 0x...} {pending}
+	// success: {account-1 map[] 0x...} {pending}
+	// success: {account-1 map[id:account-1] 0x...} {pending}
 
 }
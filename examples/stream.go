@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func fetchNumbers(ctx context.Context, keys []int) (map[int]string, error) {
+	// The batch with the larger keys is slower.
+	time.Sleep(time.Duration(keys[0]) * 10 * time.Millisecond)
+	fmt.Println("keys", keys)
+
+	res := make(map[int]string)
+	for _, k := range keys {
+		res[k] = fmt.Sprintf("number-%d", k)
+	}
+
+	return res, nil
+}
+
+func main() {
+	ctx := context.Background()
+	dl, flush := dataloader.New(ctx, fetchNumbers,
+		dataloader.WithBatchMaxKeys[int, string](3),
+		dataloader.WithBatchMaxWorker[int, string](3),
+	)
+	defer flush()
+
+	keys := []int{1, 2, 3, 10, 11, 12, 30, 31, 32}
+
+	// Results are received as soon as each batch completes, instead of
+	// waiting for the slowest batch.
+	for entry := range dl.LoadStream(ctx, keys) {
+		if entry.Err != nil {
+			fmt.Println("failed:", entry.Key, entry.Err)
+		} else {
+			fmt.Println("success:", entry.Key, entry.Value)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package dataloader
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Hedge configures hedged batches. When a batch has not completed after the
+// delay, the same keys are issued again, and the first successful call wins.
+type Hedge[K comparable, T any] struct {
+	// How long to wait before the hedged call is issued.
+	Delay time.Duration
+
+	// When set, the delay is the given percentile of the recent batch
+	// latencies, for example 0.95. The fixed delay is used until enough
+	// latencies are recorded.
+	Percentile float64
+
+	// The maximum ratio of batches that are hedged. Defaults to 0.1.
+	Budget float64
+
+	// Called for the hedged call instead of the batchFn, for example to
+	// query another replica.
+	BatchFunc BatchFunc[K, T]
+}
+
+const (
+	defaultHedgeBudget = 0.1
+	hedgeSamples       = 100
+	minHedgeSamples    = 10
+)
+
+type hedger[K comparable, T any] struct {
+	mu        sync.Mutex
+	cfg       Hedge[K, T]
+	batchFn   StreamingBatchFunc[K, T]
+	latencies []time.Duration
+	batches   int
+	hedged    int
+}
+
+func newHedger[K comparable, T any](cfg Hedge[K, T]) *hedger[K, T] {
+	if cfg.Budget <= 0 {
+		cfg.Budget = defaultHedgeBudget
+	}
+
+	h := &hedger[K, T]{cfg: cfg}
+	if cfg.BatchFunc != nil {
+		h.batchFn = cfg.BatchFunc.stream()
+	}
+
+	return h
+}
+
+// delay counts the batch, and returns how long to wait before hedging it.
+func (h *hedger[K, T]) delay() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.batches++
+
+	if h.cfg.Percentile <= 0 || len(h.latencies) < minHedgeSamples {
+		return h.cfg.Delay
+	}
+
+	sorted := append([]time.Duration(nil), h.latencies...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	i := int(h.cfg.Percentile * float64(len(sorted)))
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+
+	return sorted[i]
+}
+
+// allow reports whether the batch can be hedged within the budget.
+func (h *hedger[K, T]) allow() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if float64(h.hedged+1) > h.cfg.Budget*float64(h.batches) {
+		return false
+	}
+
+	h.hedged++
+
+	return true
+}
+
+func (h *hedger[K, T]) record(latency time.Duration) {
+	h.mu.Lock()
+	h.latencies = append(h.latencies, latency)
+	if len(h.latencies) > hedgeSamples {
+		h.latencies = h.latencies[1:]
+	}
+	h.mu.Unlock()
+}
+
+// hedge runs the batchFn, and issues the keys again if it does not complete
+// in time. The first successful call wins, and the other one is cancelled.
+func (l *Dataloader[K, T]) hedge(ctx context.Context, keys []K, emit func(K, T, error)) error {
+	if l.hedger == nil {
+		return l.batchFn(ctx, keys, emit)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	start := l.clock.Now()
+	timer := l.clock.NewTimer(l.hedger.delay())
+	defer timer.Stop()
+
+	ch := make(chan error, 2)
+	go func() {
+		ch <- l.batchFn(ctx, keys, emit)
+	}()
+
+	var err error
+	var hedged bool
+	running := 1
+	tick := timer.C()
+
+	for running > 0 {
+		select {
+		case err = <-ch:
+			running--
+
+			if err == nil {
+				l.hedger.record(l.clock.Now().Sub(start))
+
+				return nil
+			}
+
+			if !hedged {
+				return err
+			}
+		case <-tick:
+			tick = nil
+
+			if !l.hedger.allow() {
+				continue
+			}
+
+			batchFn := l.batchFn
+			if l.hedger.batchFn != nil {
+				batchFn = l.hedger.batchFn
+			}
+
+			hedged = true
+			running++
+			atomic.AddInt64(&l.stats.hedged, 1)
+			l.notify(Event[K]{
+				Type: EventHedge,
+				Keys: keys,
+			})
+
+			go func() {
+				ch <- batchFn(ctx, keys, emit)
+			}()
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,97 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func TestHedge(t *testing.T) {
+	t.Parallel()
+
+	cancelled := make(chan error, 1)
+	slowReplica := func(ctx context.Context, keys []int) (map[int]string, error) {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+
+		return nil, ctx.Err()
+	}
+
+	fastReplica := func(ctx context.Context, keys []int) (map[int]string, error) {
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	var hedged []int
+	dl, flush := dataloader.New(context.Background(), slowReplica,
+		dataloader.WithHedge(dataloader.Hedge[int, string]{
+			Delay:     10 * time.Millisecond,
+			Budget:    1,
+			BatchFunc: fastReplica,
+		}),
+		dataloader.WithObserver[int, string](func(e dataloader.Event[int]) {
+			if e.Type == dataloader.EventHedge {
+				hedged = append(hedged, e.Keys...)
+			}
+		}),
+	)
+	t.Cleanup(flush)
+
+	res, err := dl.Load(42)
+	if err != nil || res != "42" {
+		t.Fatalf("expected 42, got %v, %v", res, err)
+	}
+
+	// The losing call is cancelled.
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+
+	if exp, got := "[42]", fmt.Sprint(hedged); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if exp, got := int64(1), dl.Stats().Hedged; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestHedgeBudget(t *testing.T) {
+	t.Parallel()
+
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		time.Sleep(5 * time.Millisecond)
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	dl := dataloader.NewManual(context.Background(), fetchNumber,
+		dataloader.WithHedge(dataloader.Hedge[int, string]{
+			Delay:  time.Nanosecond,
+			Budget: 0.5,
+		}),
+	)
+
+	for i := 0; i < 10; i++ {
+		dl.Load(i)
+		dl.Dispatch()
+	}
+
+	// Hedging is capped to half of the batches.
+	if exp, got := int64(5), dl.Stats().Hedged; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
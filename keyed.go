@@ -0,0 +1,119 @@
+package dataloader
+
+import "context"
+
+// CacheKeyFunc maps a key to the comparable key used for deduplication and
+// caching, for example to normalize emails or to encode filters.
+type CacheKeyFunc[K any, C comparable] func(K) C
+
+// KeyedBatchFunc receives the original keys, one per distinct cache key, and
+// returns the values by cache key.
+type KeyedBatchFunc[K any, C comparable, T any] func(ctx context.Context, keys []K) (map[C]T, error)
+
+// Keyed is a dataloader for keys that are not comparable, or that have to be
+// normalized before they are deduplicated. The original keys are only kept
+// while they are pending, so keys refreshed ahead, which were not loaded, are
+// not refreshed.
+type Keyed[K any, C comparable, T any] struct {
+	dl    *Dataloader[C, T]
+	keyFn CacheKeyFunc[K, C]
+
+	// The original key of the pending cache keys, guarded by the dataloader
+	// lock.
+	keys map[C]K
+}
+
+func NewKeyed[K any, C comparable, T any](ctx context.Context, batchFn KeyedBatchFunc[K, C, T], keyFn CacheKeyFunc[K, C], options ...Option[C, T]) (*Keyed[K, C, T], func()) {
+	k := &Keyed[K, C, T]{
+		keyFn: keyFn,
+		keys:  make(map[C]K),
+	}
+
+	var flush func()
+	k.dl, flush = New(ctx, func(ctx context.Context, cacheKeys []C) (map[C]T, error) {
+		// The original keys are taken once per batch, as the batchFn may be
+		// called again for the same keys when bisected or hedged.
+		originals, _ := ctx.Value(k).(map[C]K)
+
+		keys := make([]K, 0, len(cacheKeys))
+		for _, c := range cacheKeys {
+			if key, ok := originals[c]; ok {
+				keys = append(keys, key)
+			}
+		}
+
+		if len(keys) == 0 {
+			return nil, nil
+		}
+
+		return batchFn(ctx, keys)
+	}, options...)
+	k.dl.batchContext = k.withOriginals
+
+	return k, flush
+}
+
+// withOriginals takes the original keys of the batch, and passes them to the
+// batchFn through the batch context.
+func (k *Keyed[K, C, T]) withOriginals(ctx context.Context, cacheKeys []C) context.Context {
+	originals := make(map[C]K, len(cacheKeys))
+
+	k.dl.mu.Lock()
+	for _, c := range cacheKeys {
+		if key, ok := k.keys[c]; ok {
+			originals[c] = key
+			delete(k.keys, c)
+		}
+	}
+	k.dl.mu.Unlock()
+
+	return context.WithValue(ctx, k, originals)
+}
+
+func (k *Keyed[K, C, T]) Load(key K) (T, error) {
+	return k.load(key).Wait()
+}
+
+func (k *Keyed[K, C, T]) LoadThunk(key K) *Result[T] {
+	return k.load(key)
+}
+
+// LoadMany returns the values in the same order as the keys.
+func (k *Keyed[K, C, T]) LoadMany(keys []K) ([]T, error) {
+	thunks := make([]*Result[T], len(keys))
+	for i, key := range keys {
+		thunks[i] = k.load(key)
+	}
+
+	result := make([]T, len(thunks))
+	for i, res := range thunks {
+		t, err := res.Wait()
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = t
+	}
+
+	return result, nil
+}
+
+func (k *Keyed[K, C, T]) Prime(key K, res T) {
+	k.dl.Prime(k.keyFn(key), res)
+}
+
+func (k *Keyed[K, C, T]) Stats() Stats {
+	return k.dl.Stats()
+}
+
+// load remembers the key that is passed to the batchFn, until its cache key
+// is batched.
+func (k *Keyed[K, C, T]) load(key K) *Result[T] {
+	c := k.keyFn(key)
+
+	return k.dl.loadMiss(c, func() {
+		if _, ok := k.keys[c]; !ok {
+			k.keys[c] = key
+		}
+	})
+}
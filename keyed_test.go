@@ -0,0 +1,184 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func TestKeyed(t *testing.T) {
+	t.Parallel()
+
+	type Filter struct {
+		Email string
+		Roles []string
+	}
+
+	var batches [][]Filter
+	fetchUsers := func(ctx context.Context, filters []Filter) (map[string]string, error) {
+		batches = append(batches, filters)
+
+		res := make(map[string]string)
+		for _, f := range filters {
+			res[strings.ToLower(f.Email)] = fmt.Sprint(f.Email, f.Roles)
+		}
+
+		return res, nil
+	}
+
+	dl, flush := dataloader.NewKeyed(context.Background(), fetchUsers, func(f Filter) string {
+		return strings.ToLower(f.Email)
+	})
+	t.Cleanup(flush)
+
+	// Pending keys with the same cache key share the result of the first
+	// key.
+	res, err := dl.LoadMany([]Filter{
+		{Email: "ABC@mail.com", Roles: []string{"admin"}},
+		{Email: "abc@mail.com"},
+		{Email: "def@mail.com"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if exp, got := "[ABC@mail.com[admin] ABC@mail.com[admin] def@mail.com[]]", fmt.Sprint(res); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if exp, got := 1, len(batches); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	// One key per distinct cache key.
+	var emails []string
+	for _, f := range batches[0] {
+		emails = append(emails, f.Email)
+	}
+	sort.Strings(emails)
+
+	if exp, got := "[ABC@mail.com def@mail.com]", fmt.Sprint(emails); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestKeyedExpired(t *testing.T) {
+	t.Parallel()
+
+	var batches []string
+	fetchUsers := func(ctx context.Context, emails []string) (map[string]string, error) {
+		batches = append(batches, fmt.Sprint(emails))
+
+		res := make(map[string]string)
+		for _, email := range emails {
+			res[strings.ToLower(email)] = email
+		}
+
+		return res, nil
+	}
+
+	clock := dataloader.NewFakeClock(time.Unix(0, 0))
+
+	dl, flush := dataloader.NewKeyed(context.Background(), fetchUsers, strings.ToLower,
+		dataloader.WithClock[string, string](clock),
+		dataloader.WithBatchMaxKeys[string, string](1),
+		dataloader.WithTTL[string, string](time.Hour),
+	)
+	t.Cleanup(flush)
+
+	for _, email := range []string{"ABC@mail.com", "abc@mail.com"} {
+		if _, err := dl.Load(email); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	// The expired key is loaded with the key that was just passed.
+	clock.Advance(time.Hour)
+
+	val, err := dl.Load("Abc@mail.com")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if exp, got := "Abc@mail.com", val; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if exp, got := "[[ABC@mail.com] [Abc@mail.com]]", fmt.Sprint(batches); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestKeyedBisect(t *testing.T) {
+	t.Parallel()
+
+	wantErr := errors.New("bad key")
+	fetchUsers := func(ctx context.Context, emails []string) (map[string]string, error) {
+		res := make(map[string]string)
+		for _, email := range emails {
+			if email == "BAD" {
+				return nil, wantErr
+			}
+
+			res[strings.ToLower(email)] = email
+		}
+
+		return res, nil
+	}
+
+	dl, flush := dataloader.NewKeyed(context.Background(), fetchUsers, strings.ToLower,
+		dataloader.WithBisect[string, string](2),
+	)
+	t.Cleanup(flush)
+
+	good, bad := dl.LoadThunk("A"), dl.LoadThunk("BAD")
+
+	// The retried halves receive the original keys too.
+	if val, err := good.Wait(); err != nil || val != "A" {
+		t.Fatalf("expected A, got %v, %v", val, err)
+	}
+
+	if _, err := bad.Wait(); !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+}
+
+func TestKeyedHedge(t *testing.T) {
+	t.Parallel()
+
+	var calls int64
+	fetchUsers := func(ctx context.Context, emails []string) (map[string]string, error) {
+		// The first call hangs, and the hedged call wins.
+		if atomic.AddInt64(&calls, 1) == 1 {
+			<-ctx.Done()
+
+			return nil, ctx.Err()
+		}
+
+		res := make(map[string]string)
+		for _, email := range emails {
+			res[strings.ToLower(email)] = email
+		}
+
+		return res, nil
+	}
+
+	dl, flush := dataloader.NewKeyed(context.Background(), fetchUsers, strings.ToLower,
+		dataloader.WithHedge(dataloader.Hedge[string, string]{
+			Delay:  10 * time.Millisecond,
+			Budget: 1,
+		}),
+	)
+	t.Cleanup(flush)
+
+	if val, err := dl.Load("ABC"); err != nil || val != "ABC" {
+		t.Fatalf("expected ABC, got %v, %v", val, err)
+	}
+}
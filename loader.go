@@ -0,0 +1,12 @@
+package dataloader
+
+// Loader is the interface implemented by Dataloader, so that consumers can
+// depend on it and substitute a fake in tests.
+type Loader[K comparable, T any] interface {
+	Load(key K) (T, error)
+	LoadThunk(key K) *Result[T]
+	LoadMany(keys []K) (map[K]T, error)
+	Prime(key K, res T)
+}
+
+var _ Loader[int, any] = (*Dataloader[int, any])(nil)
@@ -0,0 +1,150 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+)
+
+// Manual is a dataloader without a background goroutine or timers. Keys are
+// only batched when Dispatch is called.
+type Manual[K comparable, T any] struct {
+	dl   *Dataloader[K, T]
+	keys []K
+}
+
+func NewManual[K comparable, T any](ctx context.Context, batchFn BatchFunc[K, T], options ...Option[K, T]) *Manual[K, T] {
+	dl, _ := New(ctx, batchFn, options...)
+
+	return &Manual[K, T]{dl: dl}
+}
+
+// Load registers the key for the next dispatch and returns the pending result.
+func (m *Manual[K, T]) Load(key K) *Result[T] {
+	m.dl.mu.Lock()
+	defer m.dl.mu.Unlock()
+
+	res, next := m.dl.lookup(key)
+	if next != nil {
+		m.dl.pending[key] = next
+		m.keys = append(m.keys, key)
+	}
+
+	return res
+}
+
+func (m *Manual[K, T]) LoadMany(keys []K) map[K]*Result[T] {
+	result := make(map[K]*Result[T], len(keys))
+	for _, key := range keys {
+		result[key] = m.Load(key)
+	}
+
+	return result
+}
+
+func (m *Manual[K, T]) Prime(key K, res T) {
+	m.dl.Prime(key, res)
+}
+
+func (m *Manual[K, T]) PrimeMany(data map[K]T) {
+	m.dl.PrimeMany(data)
+}
+
+func (m *Manual[K, T]) PrimeError(key K, err error) {
+	m.dl.PrimeError(key, err)
+}
+
+func (m *Manual[K, T]) PrimeIfAbsent(key K, res T) bool {
+	return m.dl.PrimeIfAbsent(key, res)
+}
+
+func (m *Manual[K, T]) Replace(key K, res T) bool {
+	return m.dl.Replace(key, res)
+}
+
+func (m *Manual[K, T]) Stats() Stats {
+	return m.dl.Stats()
+}
+
+// Dispatch runs the batchFn for all pending keys, split by the batch max keys
+// and run on at most batch max worker goroutines. It returns once all the
+// pending results are settled.
+func (m *Manual[K, T]) Dispatch() {
+	refreshes := m.dl.refreshAhead()
+
+	m.dl.mu.Lock()
+	keys := append(m.keys, refreshes...)
+	m.keys = nil
+	m.dl.mu.Unlock()
+
+	batches := m.dl.partition(keys)
+	if cap(m.dl.batchMaxWorker) <= 1 {
+		for _, keys := range batches {
+			m.dl.batch(m.dl.ctx, keys)
+		}
+
+		return
+	}
+
+	// Batches of a busy partition are retried once the other batches are
+	// done.
+	for len(batches) > 0 {
+		var (
+			wg   sync.WaitGroup
+			busy [][]K
+		)
+
+		start := func(keys []K) {
+			wg.Add(1)
+			m.dl.batchMaxWorker <- struct{}{}
+
+			go func() {
+				defer func() {
+					<-m.dl.batchMaxWorker
+					m.dl.releasePartition(keys)
+					wg.Done()
+				}()
+
+				m.dl.batch(m.dl.ctx, keys)
+			}()
+		}
+
+		for _, keys := range batches {
+			if !m.dl.acquirePartition(keys) {
+				busy = append(busy, keys)
+
+				continue
+			}
+
+			start(keys)
+		}
+
+		// All the partitions are held by other dispatches, so there is
+		// nothing to wait for but their slots.
+		if len(busy) == len(batches) {
+			m.dl.waitPartition(busy[0])
+			start(busy[0])
+			busy = busy[1:]
+		}
+
+		wg.Wait()
+
+		batches = busy
+	}
+}
+
+func chunk[K any](keys []K, size int) [][]K {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if size <= 0 || len(keys) <= size {
+		return [][]K{keys}
+	}
+
+	batches := make([][]K, 0, (len(keys)+size-1)/size)
+	for size < len(keys) {
+		keys, batches = keys[size:], append(batches, keys[:size])
+	}
+
+	return append(batches, keys)
+}
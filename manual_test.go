@@ -0,0 +1,86 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func TestManual(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var batches [][]int
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		mu.Lock()
+		batches = append(batches, keys)
+		mu.Unlock()
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			if key < 0 {
+				continue
+			}
+
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	ctx := context.Background()
+
+	dl := dataloader.NewManual(ctx, fetchNumber,
+		dataloader.WithBatchMaxKeys[int, string](2),
+		dataloader.WithBatchMaxWorker[int, string](2),
+	)
+
+	results := dl.LoadMany([]int{1, 2, 3, 2, 1})
+	missing := dl.Load(-1)
+
+	_, err := missing.Peek()
+	if !errors.Is(err, dataloader.ErrNoResult) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrNoResult, err)
+	}
+
+	dl.Dispatch()
+
+	for key, res := range results {
+		val, err := res.Unwrap()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if exp, got := fmt.Sprint(key), val; exp != got {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	}
+
+	_, err = missing.Unwrap()
+	if !errors.Is(err, dataloader.ErrKeyNotFound) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrKeyNotFound, err)
+	}
+
+	var sizes []int
+	for _, keys := range batches {
+		sizes = append(sizes, len(keys))
+	}
+	sort.Ints(sizes)
+
+	if exp, got := fmt.Sprint([]int{2, 2}), fmt.Sprint(sizes); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	// Cached keys do not trigger another batch.
+	dl.Load(1)
+	dl.Dispatch()
+
+	if exp, got := 2, len(batches); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
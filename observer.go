@@ -0,0 +1,61 @@
+package dataloader
+
+type EventType int
+
+const (
+	// EventBisect is reported with the keys that still fail after a failed
+	// batch is bisected.
+	EventBisect EventType = iota + 1
+
+	// EventCircuit is reported with the new state of the circuit breaker.
+	EventCircuit
+
+	// EventHedge is reported with the keys of a batch that is hedged.
+	EventHedge
+
+	// EventRefreshError is reported with the keys whose refresh failed. The
+	// stale values are kept.
+	EventRefreshError
+
+	// EventRefreshAhead is reported with the hot keys that are refreshed
+	// before they expire.
+	EventRefreshAhead
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventBisect:
+		return "bisect"
+	case EventCircuit:
+		return "circuit"
+	case EventHedge:
+		return "hedge"
+	case EventRefreshError:
+		return "refresh error"
+	case EventRefreshAhead:
+		return "refresh ahead"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes something that happened in the dataloader.
+type Event[K comparable] struct {
+	Type  EventType
+	Keys  []K
+	Err   error
+	State CircuitState
+}
+
+func (l *Dataloader[K, T]) notify(event Event[K]) {
+	for _, fn := range l.observers {
+		fn(event)
+	}
+}
+
+func (l *Dataloader[K, T]) notifyCircuit(state CircuitState) {
+	l.notify(Event[K]{
+		Type:  EventCircuit,
+		State: state,
+	})
+}
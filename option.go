@@ -27,3 +27,171 @@ func WithBatchMaxWorker[K comparable, T any](worker int) Option[K, T] {
 		return dl
 	}
 }
+
+// WithIdleTimeout stops the background goroutine and its ticker after the
+// loader has been idle for the given duration. It is restarted on the next
+// load. A zero duration keeps it running until flush.
+func WithIdleTimeout[K comparable, T any](duration time.Duration) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.idleTimeout = duration
+
+		return dl
+	}
+}
+
+func WithClock[K comparable, T any](clock Clock) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.clock = clock
+
+		return dl
+	}
+}
+
+// WithBatchTimeout rejects all the keys of a batch with ErrBatchTimeout when
+// the batchFn does not complete in time.
+func WithBatchTimeout[K comparable, T any](timeout time.Duration) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.batchTimeout = timeout
+
+		return dl
+	}
+}
+
+// WithBisect splits a failed batch into halves and retries them recursively,
+// up to the given depth, so that only the keys that break the batch are
+// rejected.
+func WithBisect[K comparable, T any](depth int) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.bisectDepth = depth
+
+		return dl
+	}
+}
+
+// WithObserver registers a function that is called synchronously for every
+// event.
+func WithObserver[K comparable, T any](fn func(Event[K])) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.observers = append(dl.observers, fn)
+
+		return dl
+	}
+}
+
+// WithCircuitBreaker stops calling the batchFn while the downstream is
+// failing. Keys loaded while the circuit is open fail fast with
+// ErrCircuitOpen.
+func WithCircuitBreaker[K comparable, T any](cb CircuitBreaker) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.breaker = newBreaker(cb)
+
+		return dl
+	}
+}
+
+// WithRateLimit limits the rate of calls to the batchFn, counted by batch or
+// by key. Batches wait for the limiter, until the batch context is done.
+func WithRateLimit[K comparable, T any](rl RateLimit) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.limiter = newLimiter(rl)
+
+		return dl
+	}
+}
+
+// WithHedge issues a batch a second time when it is slow, within the hedging
+// budget.
+func WithHedge[K comparable, T any](hedge Hedge[K, T]) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.hedger = newHedger(hedge)
+
+		return dl
+	}
+}
+
+// WithTTL expires the cached results after the given duration. Loading an
+// expired key waits for the next batch.
+func WithTTL[K comparable, T any](ttl time.Duration) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.ttl = ttl
+
+		return dl
+	}
+}
+
+// WithStaleWhileRevalidate serves cached values older than the given duration
+// immediately, while refreshing them in the next batch. Failed refreshes keep
+// the stale value, and are reported as EventRefreshError.
+func WithStaleWhileRevalidate[K comparable, T any](staleAfter time.Duration) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.staleAfter = staleAfter
+
+		return dl
+	}
+}
+
+// WithRefreshAhead refreshes hot keys in the regular batches shortly before
+// they expire.
+func WithRefreshAhead[K comparable, T any](ra RefreshAhead) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		if ra.MinHits <= 0 {
+			ra.MinHits = 1
+		}
+
+		if ra.Interval <= 0 {
+			ra.Interval = ra.Window / 4
+		}
+
+		dl.refreshAheadCfg = &ra
+
+		return dl
+	}
+}
+
+// WithAutoPrime primes the cache with the keys returned by the batchFn that
+// were not requested.
+func WithAutoPrime[K comparable, T any](ap AutoPrime[K, T]) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.autoPrimeCfg = &ap
+
+		return dl
+	}
+}
+
+// WithClone clones the values on the way out of the results and on the way
+// into Prime, so that mutating a loaded value does not change the cached
+// value.
+func WithClone[K comparable, T any](clone func(T) T) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.clone = clone
+
+		return dl
+	}
+}
+
+// WithDeepCopy clones the values with DeepCopy.
+func WithDeepCopy[K comparable, T any]() Option[K, T] {
+	return WithClone[K, T](DeepCopy[T])
+}
+
+// WithPartition batches the keys of each partition separately, e.g. by shard
+// or tenant. The batch max keys apply per partition.
+func WithPartition[K comparable, T any, P comparable](fn func(K) P) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.partitionFn = func(key K) any {
+			return fn(key)
+		}
+
+		return dl
+	}
+}
+
+// WithPartitionMaxWorker limits the concurrent batches of each partition, so
+// that a hot partition cannot take all the batch max workers.
+func WithPartitionMaxWorker[K comparable, T any](worker int) Option[K, T] {
+	return func(dl *Dataloader[K, T]) *Dataloader[K, T] {
+		dl.partitionMaxWorker = worker
+
+		return dl
+	}
+}
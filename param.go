@@ -0,0 +1,69 @@
+package dataloader
+
+import "context"
+
+// ParamKey identifies a record by ID, loaded with the given query arguments,
+// e.g. the comments of a post ordered by the newest.
+type ParamKey[ID, A comparable] struct {
+	ID   ID
+	Args A
+}
+
+// ParamBatchFunc loads the IDs that share the same query arguments.
+type ParamBatchFunc[ID, A comparable, T any] func(ctx context.Context, args A, ids []ID) (map[ID]T, error)
+
+// NewParam returns a dataloader that collects keys within the same time
+// window, but partitions them by arguments, so that each batch calls the
+// batchFn with a single set of arguments. The arguments are combined with the
+// partitions of WithPartition.
+func NewParam[ID, A comparable, T any](ctx context.Context, batchFn ParamBatchFunc[ID, A, T], options ...Option[ParamKey[ID, A], T]) (*Dataloader[ParamKey[ID, A], T], func()) {
+	options = append(options, withParamPartition[ID, A, T]())
+
+	return NewStreaming(ctx, batchFn.stream(), options...)
+}
+
+type paramPartition struct {
+	partition any
+	args      any
+}
+
+func withParamPartition[ID, A comparable, T any]() Option[ParamKey[ID, A], T] {
+	return func(dl *Dataloader[ParamKey[ID, A], T]) *Dataloader[ParamKey[ID, A], T] {
+		partitionFn := dl.partitionFn
+		dl.partitionFn = func(key ParamKey[ID, A]) any {
+			if partitionFn == nil {
+				return key.Args
+			}
+
+			return paramPartition{
+				partition: partitionFn(key),
+				args:      key.Args,
+			}
+		}
+
+		return dl
+	}
+}
+
+func (fn ParamBatchFunc[ID, A, T]) stream() StreamingBatchFunc[ParamKey[ID, A], T] {
+	return func(ctx context.Context, keys []ParamKey[ID, A], emit func(ParamKey[ID, A], T, error)) error {
+		// The keys of a batch share the same arguments.
+		args := keys[0].Args
+
+		ids := make([]ID, len(keys))
+		for i, key := range keys {
+			ids[i] = key.ID
+		}
+
+		res, err := fn(ctx, args, ids)
+		if err != nil {
+			return err
+		}
+
+		for id, val := range res {
+			emit(ParamKey[ID, A]{ID: id, Args: args}, val, nil)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func TestParam(t *testing.T) {
+	t.Parallel()
+
+	type Args struct {
+		First   int
+		OrderBy string
+	}
+
+	type Key = dataloader.ParamKey[string, Args]
+
+	var (
+		mu    sync.Mutex
+		calls []string
+
+		running, maxRunning int64
+	)
+
+	wantErr := errors.New("bad order")
+	fetchComments := func(ctx context.Context, args Args, ids []string) (map[string]string, error) {
+		n := atomic.AddInt64(&running, 1)
+		defer atomic.AddInt64(&running, -1)
+
+		sort.Strings(ids)
+
+		mu.Lock()
+		if n > maxRunning {
+			maxRunning = n
+		}
+		calls = append(calls, fmt.Sprint(args, ids))
+		mu.Unlock()
+
+		if args.OrderBy == "" {
+			return nil, wantErr
+		}
+
+		res := make(map[string]string)
+		for _, id := range ids {
+			if id == "missing" {
+				continue
+			}
+
+			res[id] = fmt.Sprintf("%s:%d:%s", id, args.First, args.OrderBy)
+		}
+
+		return res, nil
+	}
+
+	dl, flush := dataloader.NewParam(context.Background(), fetchComments)
+	t.Cleanup(flush)
+
+	newest := Args{First: 10, OrderBy: "NEWEST"}
+	oldest := Args{First: 5, OrderBy: "OLDEST"}
+
+	keys := []Key{
+		{ID: "1", Args: newest},
+		{ID: "2", Args: newest},
+		{ID: "1", Args: oldest},
+		{ID: "missing", Args: oldest},
+		{ID: "3", Args: Args{First: 1}},
+	}
+	res := dl.LoadManyResults(keys)
+
+	for i, exp := range []string{"1:10:NEWEST", "2:10:NEWEST", "1:5:OLDEST"} {
+		got, err := res[i].Wait()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if exp != got {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	}
+
+	if _, err := res[3].Wait(); !errors.Is(err, dataloader.ErrKeyNotFound) {
+		t.Fatalf("expected %v, got %v", dataloader.ErrKeyNotFound, err)
+	}
+
+	if _, err := res[4].Wait(); !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+
+	// One call per distinct arguments.
+	sort.Strings(calls)
+	if exp, got := "[{1 } [3] {10 NEWEST} [1 2] {5 OLDEST} [1 missing]]", fmt.Sprint(calls); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	// Each set of arguments is a separate batch, run by the only worker.
+	if exp, got := int64(1), maxRunning; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
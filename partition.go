@@ -0,0 +1,127 @@
+package dataloader
+
+// partitionOf returns the partition of the key, or nil when the keys are not
+// partitioned.
+func (l *Dataloader[K, T]) partitionOf(key K) any {
+	if l.partitionFn == nil {
+		return nil
+	}
+
+	return l.partitionFn(key)
+}
+
+// group appends the keys to their partition, keeping their order, and
+// returns the partitions that were not grouped yet.
+func (l *Dataloader[K, T]) group(groups map[any][]K, keys []K) []any {
+	var added []any
+	for _, key := range keys {
+		p := l.partitionOf(key)
+		if _, ok := groups[p]; !ok {
+			added = append(added, p)
+		}
+		groups[p] = append(groups[p], key)
+	}
+
+	return added
+}
+
+// partition groups the keys by partition, keeping their order, and splits
+// each partition by the batch max keys.
+func (l *Dataloader[K, T]) partition(keys []K) [][]K {
+	groups := make(map[any][]K)
+
+	var batches [][]K
+	for _, p := range l.group(groups, keys) {
+		batches = append(batches, chunk(groups[p], l.batchMaxKeys)...)
+	}
+
+	return batches
+}
+
+// partitionWorker limits the concurrent batches of a partition. It is
+// removed once no batch of the partition holds a slot, so that idle
+// partitions do not accumulate.
+type partitionWorker struct {
+	sem  chan struct{}
+	refs int
+}
+
+// acquirePartition takes a worker slot of the partition of the keys. It
+// reports false instead of waiting when the partition is busy.
+func (l *Dataloader[K, T]) acquirePartition(keys []K) bool {
+	if l.partitionMaxWorker <= 0 {
+		return true
+	}
+
+	p := l.partitionOf(keys[0])
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, ok := l.partitionWorkers[p]
+	if !ok {
+		w = &partitionWorker{sem: make(chan struct{}, l.partitionMaxWorker)}
+		l.partitionWorkers[p] = w
+	}
+
+	select {
+	case w.sem <- struct{}{}:
+		w.refs++
+
+		return true
+	default:
+		return false
+	}
+}
+
+// waitPartition takes a worker slot of the partition of the keys, waiting
+// for one to be freed when the partition is busy.
+func (l *Dataloader[K, T]) waitPartition(keys []K) {
+	if l.partitionMaxWorker <= 0 {
+		return
+	}
+
+	p := l.partitionOf(keys[0])
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for {
+		w, ok := l.partitionWorkers[p]
+		if !ok {
+			w = &partitionWorker{sem: make(chan struct{}, l.partitionMaxWorker)}
+			l.partitionWorkers[p] = w
+		}
+
+		select {
+		case w.sem <- struct{}{}:
+			w.refs++
+
+			return
+		default:
+			l.partitionFreed.Wait()
+		}
+	}
+}
+
+// releasePartition frees the worker slot of the partition of the keys, and
+// wakes up the background goroutine and the dispatches waiting for it.
+func (l *Dataloader[K, T]) releasePartition(keys []K) {
+	if l.partitionMaxWorker <= 0 {
+		return
+	}
+
+	p := l.partitionOf(keys[0])
+
+	l.mu.Lock()
+	w := l.partitionWorkers[p]
+	<-w.sem
+	w.refs--
+	if w.refs == 0 {
+		delete(l.partitionWorkers, p)
+	}
+	l.partitionFreed.Broadcast()
+	l.mu.Unlock()
+
+	l.notifyLoop()
+}
@@ -0,0 +1,186 @@
+package dataloader_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func TestPartition(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		batches []string
+	)
+
+	fetchNumbers := func(ctx context.Context, keys []int) (map[int]int, error) {
+		mu.Lock()
+		batches = append(batches, fmt.Sprint(keys))
+		mu.Unlock()
+
+		res := make(map[int]int)
+		for _, key := range keys {
+			res[key] = key
+		}
+
+		return res, nil
+	}
+
+	dl, flush := dataloader.New(context.Background(), fetchNumbers,
+		dataloader.WithBatchMaxKeys[int, int](2),
+		dataloader.WithPartition[int, int](func(key int) bool {
+			return key%2 == 0
+		}),
+	)
+	t.Cleanup(flush)
+
+	if _, err := dl.LoadManySlice([]int{1, 2, 3, 4, 5}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	sort.Strings(batches)
+	if exp, got := "[[1 3] [2 4] [5]]", fmt.Sprint(batches); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+// Not parallel, as it counts the goroutines.
+func TestPartitionMaxWorker(t *testing.T) {
+	release := make(chan struct{})
+	fetchTenants := func(ctx context.Context, keys []string) (map[string]string, error) {
+		if strings.HasPrefix(keys[0], "hot") {
+			<-release
+		}
+
+		res := make(map[string]string)
+		for _, key := range keys {
+			res[key] = key
+		}
+
+		return res, nil
+	}
+
+	dl, flush := dataloader.New(context.Background(), fetchTenants,
+		dataloader.WithBatchMaxKeys[string, string](1),
+		dataloader.WithBatchMaxWorker[string, string](2),
+		dataloader.WithPartition[string, string](func(key string) string {
+			tenant, _, _ := strings.Cut(key, ":")
+			return tenant
+		}),
+		dataloader.WithPartitionMaxWorker[string, string](1),
+	)
+	t.Cleanup(flush)
+
+	before := runtime.NumGoroutine()
+
+	hotKeys := make([]string, 100)
+	for i := range hotKeys {
+		hotKeys[i] = fmt.Sprintf("hot:%d", i)
+	}
+	hot := dl.LoadManyResults(hotKeys)
+
+	// The hot partition takes at most one worker, so the other partitions
+	// are not starved.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	cold, err := dl.LoadThunk("cold:1").WaitContext(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if exp, got := "cold:1", cold; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	// The keys of the busy partition wait in the background goroutine, not
+	// in a goroutine each.
+	if n := runtime.NumGoroutine() - before; n > 10 {
+		t.Fatalf("expected at most 10 new goroutines, got %v", n)
+	}
+
+	close(release)
+
+	for _, res := range hot {
+		if _, err := res.Wait(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+}
+
+// Not parallel, as it counts the allocations.
+func TestPartitionMaxWorkerManual(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	fetchTenants := func(ctx context.Context, keys []string) (map[string]string, error) {
+		select {
+		case started <- struct{}{}:
+			<-release
+		default:
+		}
+
+		res := make(map[string]string)
+		for _, key := range keys {
+			res[key] = key
+		}
+
+		return res, nil
+	}
+
+	dl := dataloader.NewManual(context.Background(), fetchTenants,
+		dataloader.WithBatchMaxWorker[string, string](2),
+		dataloader.WithPartition[string, string](func(key string) string {
+			tenant, _, _ := strings.Cut(key, ":")
+			return tenant
+		}),
+		dataloader.WithPartitionMaxWorker[string, string](1),
+	)
+
+	var wg sync.WaitGroup
+	dispatch := func() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			dl.Dispatch()
+		}()
+	}
+
+	first := dl.Load("hot:1")
+	dispatch()
+	<-started
+
+	second := dl.Load("hot:2")
+	dispatch()
+
+	// The second dispatch waits for the slot held by the first one, instead
+	// of spinning.
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	time.Sleep(20 * time.Millisecond)
+	runtime.ReadMemStats(&after)
+
+	if n := after.Mallocs - before.Mallocs; n > 1000 {
+		t.Fatalf("expected at most 1000 allocations, got %v", n)
+	}
+
+	close(release)
+	wg.Wait()
+
+	for _, res := range []*dataloader.Result[string]{first, second} {
+		if _, err := res.Peek(); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+)
+
+// Pool reuses dataloaders across requests. Loaders are reset before they are
+// put back, so a pooled loader holds no goroutine and no cached data.
+type Pool[K comparable, T any] struct {
+	pool sync.Pool
+}
+
+func NewPool[K comparable, T any](ctx context.Context, batchFn BatchFunc[K, T], options ...Option[K, T]) *Pool[K, T] {
+	p := new(Pool[K, T])
+	p.pool.New = func() any {
+		dl, _ := New(ctx, batchFn, options...)
+
+		return dl
+	}
+
+	return p
+}
+
+func (p *Pool[K, T]) Get() *Dataloader[K, T] {
+	return p.pool.Get().(*Dataloader[K, T])
+}
+
+func (p *Pool[K, T]) Put(dl *Dataloader[K, T]) {
+	dl.Reset()
+	p.pool.Put(dl)
+}
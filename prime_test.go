@@ -0,0 +1,255 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func TestPrime(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan bool)
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		<-release
+
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	dl, flush := dataloader.New(context.Background(), fetchNumber)
+	t.Cleanup(flush)
+
+	n := 100
+	results := make([]string, n)
+
+	var wg sync.WaitGroup
+	wg.Add(n)
+
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+
+			results[i], _ = dl.Load(1)
+		}(i)
+	}
+
+	pending := dl.LoadThunk(1)
+
+	if dl.PrimeIfAbsent(1, "absent") {
+		t.Fatal("expected pending key not to be primed")
+	}
+
+	// The waiters of the pending key receive the primed value.
+	dl.Prime(1, "primed")
+	wg.Wait()
+
+	for _, res := range results {
+		if exp, got := "primed", res; exp != got {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	}
+
+	if res, err := pending.Wait(); err != nil || res != "primed" {
+		t.Fatalf("expected primed, got %v, %v", res, err)
+	}
+
+	// The batch result is discarded.
+	close(release)
+	dl.LoadMany([]int{2})
+
+	if res, err := dl.Load(1); err != nil || res != "primed" {
+		t.Fatalf("expected primed, got %v, %v", res, err)
+	}
+}
+
+func TestPrimeSemantics(t *testing.T) {
+	t.Parallel()
+
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	dl := dataloader.NewManual(context.Background(), fetchNumber)
+
+	if dl.Replace(1, "replaced") {
+		t.Fatal("expected absent key not to be replaced")
+	}
+
+	if !dl.PrimeIfAbsent(1, "absent") {
+		t.Fatal("expected absent key to be primed")
+	}
+
+	if dl.PrimeIfAbsent(1, "present") {
+		t.Fatal("expected cached key not to be primed")
+	}
+
+	if !dl.Replace(1, "replaced") {
+		t.Fatal("expected cached key to be replaced")
+	}
+
+	wantErr := errors.New("want error")
+	dl.PrimeError(2, wantErr)
+	dl.PrimeMany(map[int]string{3: "three", 4: "four"})
+
+	// Pending keys are settled in place.
+	pending := dl.Load(5)
+	if !dl.Replace(5, "five") {
+		t.Fatal("expected pending key to be replaced")
+	}
+
+	dl.Dispatch()
+
+	for key, exp := range map[int]string{1: "replaced", 3: "three", 4: "four", 5: "five"} {
+		if got, err := dl.Load(key).Unwrap(); err != nil || exp != got {
+			t.Fatalf("expected %v, got %v, %v", exp, got, err)
+		}
+	}
+
+	if res, err := pending.Unwrap(); err != nil || res != "five" {
+		t.Fatalf("expected five, got %v, %v", res, err)
+	}
+
+	if _, err := dl.Load(2).Unwrap(); !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+}
+
+func TestAutoPrime(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	fetchSiblings := func(ctx context.Context, keys []int) (map[int]string, error) {
+		calls++
+
+		// Returns the siblings of each key as well.
+		res := make(map[int]string)
+		for _, key := range keys {
+			for i := key; i < key+5; i++ {
+				res[i] = fmt.Sprint(i)
+			}
+		}
+
+		return res, nil
+	}
+
+	dl := dataloader.NewManual(context.Background(), fetchSiblings,
+		dataloader.WithAutoPrime(dataloader.AutoPrime[int, string]{
+			Filter: func(key int, val string) bool {
+				return key != 4
+			},
+			MaxKeys: 3,
+		}),
+	)
+
+	dl.Load(1)
+	dl.Dispatch()
+
+	// Key 4 is filtered, and only two of the other siblings fit.
+	results := dl.LoadMany([]int{2, 3, 4, 5})
+	if results[4].Ready() {
+		t.Fatal("expected filtered key not to be primed")
+	}
+
+	var primed int
+	for _, res := range results {
+		if res.Ready() {
+			primed++
+		}
+	}
+
+	if exp, got := 2, primed; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if exp, got := int64(2), dl.Stats().AutoPrimed; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	if exp, got := 1, calls; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestPrimeInto(t *testing.T) {
+	t.Parallel()
+
+	type User struct {
+		ID    string
+		Email string
+	}
+
+	var calls int32
+	fetchByID := func(ctx context.Context, ids []string) (map[string]User, error) {
+		atomic.AddInt32(&calls, 1)
+
+		res := make(map[string]User)
+		for _, id := range ids {
+			res[id] = User{ID: id, Email: id + "@mail.com"}
+		}
+
+		return res, nil
+	}
+
+	fetchByEmail := func(ctx context.Context, emails []string) (map[string]User, error) {
+		atomic.AddInt32(&calls, 1)
+
+		res := make(map[string]User)
+		for _, email := range emails {
+			res[email] = User{ID: strings.TrimSuffix(email, "@mail.com"), Email: email}
+		}
+
+		return res, nil
+	}
+
+	ctx := context.Background()
+
+	byID, flush := dataloader.New(ctx, fetchByID)
+	t.Cleanup(flush)
+
+	byEmail, flush := dataloader.New(ctx, fetchByEmail)
+	t.Cleanup(flush)
+
+	dataloader.PrimeInto(byEmail, byID, func(u User) string {
+		return u.ID
+	})
+	dataloader.PrimeInto(byID, byEmail, func(u User) string {
+		return u.Email
+	})
+
+	if _, err := byEmail.Load("alice@mail.com"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if _, err := byID.Load("bob"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Both are primed by the other loader.
+	if !byID.LoadThunk("alice").Ready() {
+		t.Fatal("expected user to be primed by ID")
+	}
+
+	if !byEmail.LoadThunk("bob@mail.com").Ready() {
+		t.Fatal("expected user to be primed by email")
+	}
+
+	if exp, got := int32(2), atomic.LoadInt32(&calls); exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+}
@@ -0,0 +1,105 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimit configures a token bucket that limits how often the batchFn is
+// called.
+type RateLimit struct {
+	// How many tokens are added per second. Defaults to Burst.
+	Rate float64
+
+	// How many tokens can be accumulated. Defaults to 1.
+	Burst int
+
+	// Whether each key takes a token, instead of each batch.
+	PerKey bool
+}
+
+type limiter struct {
+	mu     sync.Mutex
+	cfg    RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(cfg RateLimit) *limiter {
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+
+	// A non-positive rate never refills the bucket, and its wait overflows.
+	if cfg.Rate <= 0 {
+		cfg.Rate = float64(cfg.Burst)
+	}
+
+	return &limiter{
+		cfg:    cfg,
+		tokens: float64(cfg.Burst),
+	}
+}
+
+// reserve takes n tokens, and returns how long to wait until they are
+// available.
+func (r *limiter) reserve(now time.Time, n float64) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.last.IsZero() {
+		r.tokens += now.Sub(r.last).Seconds() * r.cfg.Rate
+		if burst := float64(r.cfg.Burst); r.tokens > burst {
+			r.tokens = burst
+		}
+	}
+
+	r.last = now
+	r.tokens -= n
+	if r.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-r.tokens / r.cfg.Rate * float64(time.Second))
+}
+
+// cancel returns the tokens of a reservation that was not used.
+func (r *limiter) cancel(n float64) {
+	r.mu.Lock()
+	r.tokens += n
+	r.mu.Unlock()
+}
+
+// wait blocks until the rate limiter allows the batch, or the context is done.
+func (l *Dataloader[K, T]) wait(ctx context.Context, keys []K) error {
+	if l.limiter == nil {
+		return nil
+	}
+
+	n := 1.0
+	if l.limiter.cfg.PerKey {
+		n = float64(len(keys))
+	}
+
+	d := l.limiter.reserve(l.clock.Now(), n)
+	if d <= 0 {
+		return nil
+	}
+
+	timer := l.clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.limiter.cancel(n)
+
+		return ctx.Err()
+	case <-timer.C():
+		atomic.AddInt64(&l.stats.rateLimited, 1)
+		atomic.AddInt64(&l.stats.rateLimitWait, int64(d))
+
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+package dataloader_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/alextanhongpin/dataloader"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	clock := dataloader.NewFakeClock(time.Unix(0, 0))
+
+	dl, flush := dataloader.New(context.Background(), fetchNumber,
+		dataloader.WithClock[int, string](clock),
+		dataloader.WithBatchMaxKeys[int, string](2),
+		dataloader.WithRateLimit[int, string](dataloader.RateLimit{
+			Rate:   2,
+			Burst:  2,
+			PerKey: true,
+		}),
+	)
+	t.Cleanup(flush)
+
+	// The first batch takes the burst.
+	if _, err := dl.LoadMany([]int{1, 2}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The second batch waits a second for two more tokens.
+	res := dl.LoadManyThunk([]int{3, 4})
+	for !res.Ready() {
+		clock.Advance(100 * time.Millisecond)
+		runtime.Gosched()
+	}
+
+	if _, err := res.Wait(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	stats := dl.Stats()
+	if exp, got := int64(1), stats.RateLimited; exp != got {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+
+	// Less if the clock moved before the tokens were reserved.
+	if wait := stats.RateLimitWait; wait <= 0 || wait > time.Second {
+		t.Fatalf("expected wait of at most 1s, got %v", wait)
+	}
+}
+
+func TestRateLimitDefaultRate(t *testing.T) {
+	t.Parallel()
+
+	fetchNumber := func(ctx context.Context, keys []int) (map[int]string, error) {
+		res := make(map[int]string)
+		for _, key := range keys {
+			res[key] = fmt.Sprint(key)
+		}
+
+		return res, nil
+	}
+
+	clock := dataloader.NewFakeClock(time.Unix(0, 0))
+
+	dl, flush := dataloader.New(context.Background(), fetchNumber,
+		dataloader.WithClock[int, string](clock),
+		dataloader.WithBatchMaxKeys[int, string](1),
+		dataloader.WithRateLimit[int, string](dataloader.RateLimit{}),
+	)
+	t.Cleanup(flush)
+
+	if _, err := dl.Load(1); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Without a rate, the bucket refills its burst every second, instead of
+	// not limiting at all.
+	res := dl.LoadThunk(2)
+	for !res.Ready() {
+		clock.Advance(100 * time.Millisecond)
+		runtime.Gosched()
+	}
+
+	if wait := dl.Stats().RateLimitWait; wait <= 0 || wait > time.Second {
+		t.Fatalf("expected wait of at most 1s, got %v", wait)
+	}
+}
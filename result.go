@@ -1,27 +1,48 @@
 package dataloader
 
 import (
+	"context"
 	"errors"
 	"sync"
 )
 
 var (
-	ErrKeyNotFound = errors.New("key not found")
-	ErrNoResult    = errors.New("no result")
-	ErrTerminated  = errors.New("terminated")
+	ErrBatchTimeout = errors.New("batch timeout")
+	ErrCircuitOpen  = errors.New("circuit open")
+	ErrKeyNotFound  = errors.New("key not found")
+	ErrNoResult     = errors.New("no result")
+	ErrTerminated   = errors.New("terminated")
 )
 
+// Result is the eventual value of a key. It is settled exactly once, either
+// resolved with a value or rejected with an error.
 type Result[T any] struct {
 	res   T
 	err   error
-	dirty bool
+	done  chan struct{}
+	init  sync.Once
 	once  sync.Once
+	clone func(T) T
+
+	// Called once the result is settled.
+	mu   sync.Mutex
+	subs []func()
+}
+
+// Resolve returns a result that is resolved with the value.
+func Resolve[T any](t T) *Result[T] {
+	return new(Result[T]).resolve(t)
+}
+
+// Reject returns a result that is rejected with the error.
+func Reject[T any](err error) *Result[T] {
+	return new(Result[T]).reject(err)
 }
 
 func (r *Result[T]) resolve(t T) *Result[T] {
 	r.once.Do(func() {
 		r.res = t
-		r.dirty = true
+		r.settle()
 	})
 
 	return r
@@ -30,20 +51,60 @@ func (r *Result[T]) resolve(t T) *Result[T] {
 func (r *Result[T]) reject(err error) *Result[T] {
 	r.once.Do(func() {
 		r.err = err
-		r.dirty = true
+		r.settle()
 	})
 
 	return r
 }
 
+// settle wakes up the waiters and calls the subscribers.
+func (r *Result[T]) settle() {
+	r.mu.Lock()
+	close(r.ch())
+	subs := r.subs
+	r.subs = nil
+	r.mu.Unlock()
+
+	for _, fn := range subs {
+		fn()
+	}
+}
+
+// subscribe calls fn once the result is settled, on the goroutine that
+// settles it, or immediately if it is already settled. fn must not block.
+func (r *Result[T]) subscribe(fn func()) {
+	r.mu.Lock()
+	if !r.Ready() {
+		r.subs = append(r.subs, fn)
+		r.mu.Unlock()
+
+		return
+	}
+	r.mu.Unlock()
+
+	fn()
+}
+
+func (r *Result[T]) ch() chan struct{} {
+	r.init.Do(func() {
+		r.done = make(chan struct{})
+	})
+
+	return r.done
+}
+
+// Result returns the value without blocking, or the zero value if the result
+// is not settled yet.
 func (r *Result[T]) Result() (t T) {
 	if r.IsZero() {
 		return
 	}
 
-	return r.res
+	return r.value()
 }
 
+// Error returns the error without blocking, or ErrNoResult if the result is
+// not settled yet.
 func (r *Result[T]) Error() error {
 	if r.IsZero() {
 		return ErrNoResult
@@ -52,10 +113,63 @@ func (r *Result[T]) Error() error {
 	return r.err
 }
 
+// Unwrap blocks until the result is settled, like Wait.
 func (r *Result[T]) Unwrap() (t T, err error) {
+	return r.Wait()
+}
+
+// Peek returns the result without blocking, or ErrNoResult if the result is
+// not settled yet.
+func (r *Result[T]) Peek() (t T, err error) {
 	return r.Result(), r.Error()
 }
 
+// Wait blocks until the result is settled.
+func (r *Result[T]) Wait() (T, error) {
+	<-r.ch()
+
+	return r.value(), r.err
+}
+
+// WaitContext blocks until the result is settled or the context is done.
+func (r *Result[T]) WaitContext(ctx context.Context) (t T, err error) {
+	select {
+	case <-ctx.Done():
+		return t, ctx.Err()
+	case <-r.ch():
+		return r.value(), r.err
+	}
+}
+
+// Done returns a channel that is closed when the result is settled.
+func (r *Result[T]) Done() <-chan struct{} {
+	return r.ch()
+}
+
+// Ready reports whether the result is settled.
+func (r *Result[T]) Ready() bool {
+	if r == nil {
+		return false
+	}
+
+	select {
+	case <-r.ch():
+		return true
+	default:
+		return false
+	}
+}
+
 func (r *Result[T]) IsZero() bool {
-	return r == nil || !r.dirty
+	return !r.Ready()
+}
+
+// value returns a clone of the value, so that callers cannot mutate the
+// cached value.
+func (r *Result[T]) value() T {
+	if r.clone == nil || r.err != nil {
+		return r.res
+	}
+
+	return r.clone(r.res)
 }
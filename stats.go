@@ -0,0 +1,42 @@
+package dataloader
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats are the counters of a dataloader since it was created.
+type Stats struct {
+	// How many batches waited for the rate limiter.
+	RateLimited int64
+
+	// How long batches waited for the rate limiter in total.
+	RateLimitWait time.Duration
+
+	// How many batches were hedged.
+	Hedged int64
+
+	// How many keys were refreshed ahead of their expiry.
+	RefreshedAhead int64
+
+	// How many unrequested keys returned by the batchFn were primed.
+	AutoPrimed int64
+}
+
+type stats struct {
+	rateLimited    int64
+	rateLimitWait  int64
+	hedged         int64
+	refreshedAhead int64
+	autoPrimed     int64
+}
+
+func (l *Dataloader[K, T]) Stats() Stats {
+	return Stats{
+		RateLimited:    atomic.LoadInt64(&l.stats.rateLimited),
+		RateLimitWait:  time.Duration(atomic.LoadInt64(&l.stats.rateLimitWait)),
+		Hedged:         atomic.LoadInt64(&l.stats.hedged),
+		RefreshedAhead: atomic.LoadInt64(&l.stats.refreshedAhead),
+		AutoPrimed:     atomic.LoadInt64(&l.stats.autoPrimed),
+	}
+}
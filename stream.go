@@ -0,0 +1,81 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+)
+
+// Entry is the result of a single key.
+type Entry[K comparable, T any] struct {
+	Key   K
+	Value T
+	Err   error
+}
+
+// LoadStream loads the keys and sends the result of each distinct key as soon
+// as its batch completes, so that callers do not have to wait for the slowest
+// batch. The channel is closed once all the results are sent, or when the
+// context is done.
+func (l *Dataloader[K, T]) LoadStream(ctx context.Context, keys []K) <-chan Entry[K, T] {
+	thunks := l.loadMany(keys)
+
+	// Buffered, so that the settling batches never block on the receiver.
+	ch := make(chan Entry[K, T], len(thunks))
+	done := make(chan struct{})
+
+	var mu sync.Mutex
+	n := len(thunks)
+	finish := func() {
+		select {
+		case <-done:
+		default:
+			close(done)
+			close(ch)
+		}
+	}
+
+	if n == 0 {
+		finish()
+
+		return ch
+	}
+
+	// The results are sent by the batches that settle them, instead of a
+	// goroutine waiting for each key.
+	for key, res := range thunks {
+		key, res := key, res
+		res.subscribe(func() {
+			mu.Lock()
+			defer mu.Unlock()
+
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			t, err := res.Wait()
+			ch <- Entry[K, T]{
+				Key:   key,
+				Value: t,
+				Err:   err,
+			}
+
+			if n--; n == 0 {
+				finish()
+			}
+		})
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			finish()
+			mu.Unlock()
+		case <-done:
+		}
+	}()
+
+	return ch
+}